@@ -0,0 +1,423 @@
+// shpdump prints records of ESRI shapefile dataset for debugging: record number, byte offset,
+// content length, shape type, box, parts and points together with .shx entry and dBase row.
+//
+// Usage:
+//
+//	shpdump [-r 1-10,15,20-] [-hex] [-points N] file.shp
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/raspi/GeoESRIShapeFile/common"
+	"github.com/raspi/GeoESRIShapeFile/dbf"
+	"github.com/raspi/GeoESRIShapeFile/shp"
+	"github.com/raspi/GeoESRIShapeFile/shx"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Inclusive range of 1-based record numbers, To 0 means until end
+type recordRange struct {
+	From, To uint32
+}
+
+type recordRanges []recordRange
+
+func (rr recordRanges) String() string {
+	var s []string
+	for _, r := range rr {
+		switch {
+		case r.To == 0:
+			s = append(s, fmt.Sprintf(`%d-`, r.From))
+		case r.From == r.To:
+			s = append(s, fmt.Sprintf(`%d`, r.From))
+		default:
+			s = append(s, fmt.Sprintf(`%d-%d`, r.From, r.To))
+		}
+	}
+
+	return strings.Join(s, `,`)
+}
+
+// Parse "1-10,15,20-"
+func (rr *recordRanges) Set(s string) error {
+	for _, part := range strings.Split(s, `,`) {
+		part = strings.TrimSpace(part)
+		if part == `` {
+			continue
+		}
+
+		var r recordRange
+		from, to := part, part
+		if i := strings.Index(part, `-`); i >= 0 {
+			from, to = part[:i], part[i+1:]
+		}
+
+		n, err := strconv.ParseUint(from, 10, 32)
+		if err != nil || n == 0 {
+			return fmt.Errorf(`invalid record number %q, records start from 1`, from)
+		}
+		r.From = uint32(n)
+
+		if to != `` {
+			n, err = strconv.ParseUint(to, 10, 32)
+			if err != nil || uint32(n) < r.From {
+				return fmt.Errorf(`invalid range %q`, part)
+			}
+			r.To = uint32(n)
+		}
+
+		*rr = append(*rr, r)
+	}
+
+	sort.Slice(*rr, func(i, j int) bool { return (*rr)[i].From < (*rr)[j].From })
+
+	return nil
+}
+
+func (rr recordRanges) contains(n uint32) bool {
+	if len(rr) == 0 {
+		return true
+	}
+
+	for _, r := range rr {
+		if n >= r.From && (r.To == 0 || n <= r.To) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Is n past all ranges
+func (rr recordRanges) done(n uint32) bool {
+	for _, r := range rr {
+		if r.To == 0 || n <= r.To {
+			return false
+		}
+	}
+
+	return len(rr) > 0
+}
+
+type dumper struct {
+	w         io.Writer
+	shp       *shp.ShapeFile
+	shx       *shx.IndexRecordLookupFile // nil if .shx is missing or broken
+	dbf       *dbf.DBaseFile             // nil if .dbf is missing or broken
+	hex       bool
+	maxPoints int
+}
+
+func main() {
+	var ranges recordRanges
+	flag.Var(&ranges, `r`, `record numbers to dump (1-based), for example 1-10,15,20-`)
+	hexDump := flag.Bool(`hex`, false, `hex view of raw record bytes`)
+	maxPoints := flag.Int(`points`, -1, `max points to print per record, -1 for all`)
+
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [-r 1-10,15,20-] [-hex] [-points N] file.shp\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	fpath := flag.Arg(0)
+
+	// Files are opened one by one instead of geoesrishapefile.Open so that broken .shx or .dbf
+	// doesn't prevent dumping .shp
+	fshp, err := shp.New(fpath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer fshp.Close()
+
+	err = fshp.Initialize()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v: %v\n", fpath, err)
+		os.Exit(1)
+	}
+
+	d := dumper{
+		w:         os.Stdout,
+		shp:       &fshp,
+		hex:       *hexDump,
+		maxPoints: *maxPoints,
+	}
+
+	if p, ok := sidecar(fpath, `shx`); ok {
+		fshx, err := shx.New(p)
+		if err == nil {
+			defer fshx.Close()
+			err = fshx.Initialize()
+		}
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v: %v, scanning .shp without index\n", p, err)
+		} else {
+			d.shx = &fshx
+		}
+	}
+
+	if p, ok := sidecar(fpath, `dbf`); ok {
+		fdbf, err := dbf.Open(p)
+		if err == nil {
+			defer fdbf.Close()
+			err = fdbf.Initialize()
+		}
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v: %v, dumping without dBase rows\n", p, err)
+		} else {
+			d.dbf = &fdbf
+		}
+	}
+
+	fmt.Fprintf(d.w, "%v %v\n\n", fshp.Header1, fshp.Header2)
+
+	if d.shx != nil {
+		err = d.dumpWithIndex(ranges)
+	} else {
+		err = d.dumpSequential(ranges)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// File with base name of fpath and given extension in any case
+func sidecar(fpath, ext string) (string, bool) {
+	dir, name := filepath.Split(fpath)
+	base := strings.TrimSuffix(name, filepath.Ext(name))
+
+	if dir == `` {
+		dir = `.`
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ``, false
+	}
+
+	for _, e := range entries {
+		name := e.Name()
+		if strings.HasPrefix(name, base+`.`) && strings.EqualFold(name[len(base)+1:], ext) {
+			return filepath.Join(dir, e.Name()), true
+		}
+	}
+
+	return ``, false
+}
+
+// Use .shx offsets to jump directly to selected records
+func (d *dumper) dumpWithIndex(ranges recordRanges) error {
+	count := uint32(d.shx.GetHeaderRecordCount())
+
+	for n := uint32(1); n <= count; n++ {
+		if !ranges.contains(n) {
+			if ranges.done(n) {
+				break
+			}
+
+			continue
+		}
+
+		e, err := d.shx.ReadRecordAt(uint(n - 1))
+		if err != nil {
+			return fmt.Errorf(`error reading .shx entry #%d: %v`, n, err)
+		}
+
+		rec, err := d.shp.ReadRawRecordAt(e.Offset)
+		d.dumpRecord(n, &e, rec, err)
+	}
+
+	return nil
+}
+
+// No .shx, scan .shp from start
+func (d *dumper) dumpSequential(ranges recordRanges) error {
+	offset := int64(100)
+
+	for n := uint32(1); ; n++ {
+		if ranges.done(n) {
+			break
+		}
+
+		rec, err := d.shp.ReadRawRecordAt(offset)
+		if err == io.EOF {
+			break
+		}
+
+		if ranges.contains(n) {
+			d.dumpRecord(n, nil, rec, err)
+		}
+
+		if err != nil {
+			// Can't continue without knowing where next record starts
+			return fmt.Errorf(`stopped at record #%d: %v`, n, err)
+		}
+
+		offset += 8 + int64(rec.Header.Length)
+	}
+
+	return nil
+}
+
+func (d *dumper) dumpRecord(n uint32, o *shx.Entry, rec shp.RawRecord, readErr error) {
+	fmt.Fprintf(d.w, "Record #%d\n", n)
+
+	if o != nil {
+		fmt.Fprintf(d.w, "  .shx:      %v\n", o)
+	}
+
+	if readErr != nil {
+		fmt.Fprintf(d.w, "  error:     %v\n", readErr)
+	}
+
+	if len(rec.RawHeader) == 8 {
+		// Header.Length can't hold lengths over 32 bits
+		length := common.WordsToBytes(rec.RawHeader[4:])
+
+		fmt.Fprintf(d.w, "  offset:    0x%04[1]x (%06[1]d)\n", rec.Offset)
+		fmt.Fprintf(d.w, "  header:    number %d, content length 0x%04[2]x (%06[2]d)\n", rec.Header.Number, length)
+
+		if rec.Header.Number != n {
+			fmt.Fprintf(d.w, "  warning:   record number is %d, should be %d\n", rec.Header.Number, n)
+		}
+
+		if o != nil && o.Length != length {
+			fmt.Fprintf(d.w, "  warning:   .shx length %d differs from record length %d\n", o.Length, length)
+		}
+
+		if readErr != nil && rec.Content != nil {
+			fmt.Fprintf(d.w, "  warning:   only %d bytes of content before end of file\n", len(rec.Content))
+		}
+	}
+
+	if readErr == nil {
+		shape, err := shp.DecodeRecord(rec.Content)
+		if err != nil {
+			fmt.Fprintf(d.w, "  error:     %v\n", err)
+		} else {
+			d.dumpShape(shape)
+		}
+	}
+
+	if d.dbf != nil {
+		row, err := d.dbf.ReadRecordAt(int(n - 1))
+		if err != nil {
+			fmt.Fprintf(d.w, "  .dbf:      %v\n", err)
+		} else {
+			fmt.Fprintf(d.w, "  .dbf:      %v\n", formatRow(d.dbf.FieldDescriptors, row))
+		}
+	}
+
+	if d.hex && len(rec.RawHeader) > 0 {
+		fmt.Fprintf(d.w, "  raw:\n")
+		hexDump(d.w, rec)
+	}
+
+	fmt.Fprintln(d.w)
+}
+
+// Print shape type, box, parts and points with reflection so that all shape types are handled
+func (d *dumper) dumpShape(shape shp.ShapeTypeI) {
+	v := reflect.ValueOf(shape)
+	t := v.Type()
+
+	fmt.Fprintf(d.w, "  type:      %v\n", t.Name())
+
+	if t.Kind() != reflect.Struct || t.NumField() == 0 {
+		return
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		name := t.Field(i).Name
+		f := v.Field(i)
+
+		if name == `Points` {
+			points := f.Interface().([]shp.Point)
+			fmt.Fprintf(d.w, "  %-10s %d\n", name+`:`, len(points))
+
+			for idx, p := range points {
+				if d.maxPoints >= 0 && idx >= d.maxPoints {
+					fmt.Fprintf(d.w, "    ...\n")
+					break
+				}
+
+				fmt.Fprintf(d.w, "    [%d] %v\n", idx, p)
+			}
+
+			continue
+		}
+
+		if f.Kind() == reflect.Slice && d.maxPoints >= 0 && f.Len() > d.maxPoints {
+			fmt.Fprintf(d.w, "  %-10s %v ...\n", name+`:`, f.Slice(0, d.maxPoints).Interface())
+			continue
+		}
+
+		fmt.Fprintf(d.w, "  %-10s %v\n", name+`:`, f.Interface())
+	}
+}
+
+func formatRow(fields []dbf.FieldDescriptor, row map[string]dbf.Record) string {
+	var s []string
+	for _, f := range fields {
+		rec, ok := row[f.Name]
+		if !ok {
+			continue
+		}
+
+		s = append(s, fmt.Sprintf(`%v=%v`, f.Name, rec))
+	}
+
+	return strings.Join(s, ` `)
+}
+
+// Hex view with absolute file offsets, record header included
+func hexDump(w io.Writer, rec shp.RawRecord) {
+	data := append(append([]byte{}, rec.RawHeader...), rec.Content...)
+
+	for start := 0; start < len(data); start += 16 {
+		end := start + 16
+		if end > len(data) {
+			end = len(data)
+		}
+
+		var hexPart, asciiPart strings.Builder
+		for i := start; i < start+16; i++ {
+			if i == start+8 {
+				hexPart.WriteByte(' ')
+			}
+
+			if i >= end {
+				hexPart.WriteString(`   `)
+				continue
+			}
+
+			fmt.Fprintf(&hexPart, `%02x `, data[i])
+
+			c := data[i]
+			if c < 0x20 || c > 0x7e {
+				c = '.'
+			}
+			asciiPart.WriteByte(c)
+		}
+
+		fmt.Fprintf(w, "    %08x  %s |%s|\n", rec.Offset+int64(start), hexPart.String(), asciiPart.String())
+	}
+}
@@ -0,0 +1,298 @@
+// shpinfo prints summary of ESRI shapefile dataset: shape type, bounding box, record counts,
+// dBase schema and which sidecar files are present.
+//
+// Usage:
+//
+//	shpinfo [-json] file.shp [file2.shp ...]
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	geoesrishapefile "github.com/raspi/GeoESRIShapeFile"
+	"github.com/raspi/GeoESRIShapeFile/dbf"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// Known sidecar files, in print order
+var sidecars = []struct {
+	Ext         string
+	Description string
+}{
+	{`shp`, `geometry`},
+	{`shx`, `geometry index`},
+	{`dbf`, `attributes`},
+	{`prj`, `projection`},
+	{`cpg`, `code page`},
+	{`sbn`, `spatial bin index`},
+	{`sbx`, `spatial bin index offsets`},
+	{`qix`, `quadtree spatial index`},
+	{`fbn`, `read-only spatial index`},
+	{`fbx`, `read-only spatial index offsets`},
+	{`ain`, `attribute index`},
+	{`aih`, `attribute index`},
+	{`atx`, `attribute index`},
+	{`ixs`, `geocoding index`},
+	{`mxs`, `geocoding index`},
+	{`xml`, `metadata`},
+}
+
+type boxInfo struct {
+	MinX float64 `json:"min_x"`
+	MinY float64 `json:"min_y"`
+	MaxX float64 `json:"max_x"`
+	MaxY float64 `json:"max_y"`
+	MinZ float64 `json:"min_z"`
+	MaxZ float64 `json:"max_z"`
+	MinM float64 `json:"min_m"`
+	MaxM float64 `json:"max_m"`
+}
+
+type fieldInfo struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Length   int    `json:"length"`
+	Decimals int    `json:"decimals"`
+}
+
+type dbfInfo struct {
+	Version        string      `json:"version"`
+	Date           string      `json:"date"`
+	LanguageDriver uint8       `json:"language_driver"`
+	CodePage       int         `json:"code_page,omitempty"`
+	Cpg            string      `json:"cpg,omitempty"`
+	RecordCount    int         `json:"record_count"`
+	RecordSize     int         `json:"record_size"`
+	Fields         []fieldInfo `json:"fields"`
+}
+
+type sidecarInfo struct {
+	Ext         string `json:"ext"`
+	Description string `json:"description"`
+	Present     bool   `json:"present"`
+	Path        string `json:"path,omitempty"`
+}
+
+type info struct {
+	Path           string        `json:"path"`
+	ShapeType      string        `json:"shape_type"`
+	ShapeTypeCode  int32         `json:"shape_type_code"`
+	FileLength     int64         `json:"file_length"`
+	Box            boxInfo       `json:"bbox"`
+	ShxRecordCount *uint         `json:"shx_record_count,omitempty"`
+	Dbf            *dbfInfo      `json:"dbf,omitempty"`
+	Sidecars       []sidecarInfo `json:"sidecars"`
+	Other          []string      `json:"other,omitempty"` // Files with same base name but unknown extension
+}
+
+func main() {
+	jsonOutput := flag.Bool(`json`, false, `JSON output`)
+
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [-json] file.shp [file2.shp ...]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var infos []info
+	failed := false
+
+	for _, fpath := range flag.Args() {
+		i, err := readInfo(fpath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v: %v\n", fpath, err)
+			failed = true
+			continue
+		}
+
+		infos = append(infos, i)
+	}
+
+	if *jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent(``, `  `)
+
+		var err error
+		if len(infos) == 1 && flag.NArg() == 1 {
+			err = enc.Encode(infos[0])
+		} else {
+			err = enc.Encode(infos)
+		}
+
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	} else {
+		for idx, i := range infos {
+			if idx > 0 {
+				fmt.Println()
+			}
+
+			printText(os.Stdout, i)
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func readInfo(fpath string) (i info, err error) {
+	sf, err := geoesrishapefile.Open(fpath)
+	if err != nil {
+		return i, err
+	}
+	defer sf.Close()
+
+	i.Path = fpath
+	if p, ok := sf.Files[`shp`]; ok {
+		i.Path = p
+
+		hdr2 := sf.Fshp.Header2
+		i.ShapeType = hdr2.ShapeType.String()
+		i.ShapeTypeCode = int32(hdr2.ShapeType)
+		i.FileLength = int64(sf.Fshp.Header1.Length) * 2
+		i.Box = boxInfo{
+			MinX: hdr2.Min.X, MinY: hdr2.Min.Y,
+			MaxX: hdr2.Max.X, MaxY: hdr2.Max.Y,
+			MinZ: hdr2.Z.Min, MaxZ: hdr2.Z.Max,
+			MinM: hdr2.M.Min, MaxM: hdr2.M.Max,
+		}
+	}
+
+	if _, ok := sf.Files[`shx`]; ok {
+		count := sf.Fshx.GetHeaderRecordCount()
+		i.ShxRecordCount = &count
+	}
+
+	if _, ok := sf.Files[`dbf`]; ok {
+		hdr := sf.Fdbf.Header
+		d := dbfInfo{
+			Version:        hdr.Version.String(),
+			Date:           hdr.Date.Format(`2006-01-02`),
+			LanguageDriver: uint8(hdr.LanguageDriver),
+			CodePage:       hdr.LanguageDriver.CodePage(),
+			RecordCount:    hdr.RecordCount,
+			RecordSize:     hdr.RecordSize,
+		}
+
+		for _, f := range sf.Fdbf.FieldDescriptors {
+			d.Fields = append(d.Fields, fieldInfo{
+				Name:     f.Name,
+				Type:     f.Type.String(),
+				Length:   f.Length,
+				Decimals: f.DecimalCount,
+			})
+		}
+
+		if p, ok := sf.Files[`cpg`]; ok {
+			b, err := ioutil.ReadFile(p)
+			if err != nil {
+				return i, err
+			}
+
+			d.Cpg = strings.TrimSpace(string(b))
+		}
+
+		i.Dbf = &d
+	}
+
+	// ArcGIS metadata is named file.shp.xml, so it doesn't share base name with file.shp
+	if p, ok := sf.Files[`shp`]; ok && sf.Files[`xml`] == `` {
+		for _, ext := range []string{`.xml`, `.XML`} {
+			if _, err := os.Stat(p + ext); err == nil {
+				sf.Files[`xml`] = p + ext
+				break
+			}
+		}
+	}
+
+	known := make(map[string]bool)
+	for _, s := range sidecars {
+		p, ok := sf.Files[s.Ext]
+		i.Sidecars = append(i.Sidecars, sidecarInfo{Ext: s.Ext, Description: s.Description, Present: ok, Path: p})
+		known[s.Ext] = true
+	}
+
+	for ext, p := range sf.Files {
+		if !known[ext] {
+			i.Other = append(i.Other, p)
+		}
+	}
+
+	sort.Strings(i.Other)
+
+	return i, nil
+}
+
+func printText(w io.Writer, i info) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "File:\t%v\n", i.Path)
+
+	if i.ShapeType != `` {
+		fmt.Fprintf(tw, "Shape type:\t%v (%d)\n", i.ShapeType, i.ShapeTypeCode)
+		fmt.Fprintf(tw, "File length:\t%d bytes\n", i.FileLength)
+		fmt.Fprintf(tw, "Bounding box:\tX %v .. %v\n", i.Box.MinX, i.Box.MaxX)
+		fmt.Fprintf(tw, "\tY %v .. %v\n", i.Box.MinY, i.Box.MaxY)
+		fmt.Fprintf(tw, "\tZ %v .. %v\n", i.Box.MinZ, i.Box.MaxZ)
+		fmt.Fprintf(tw, "\tM %v .. %v\n", i.Box.MinM, i.Box.MaxM)
+	}
+
+	if i.ShxRecordCount != nil {
+		fmt.Fprintf(tw, "Records (.shx):\t%d\n", *i.ShxRecordCount)
+	}
+
+	if i.Dbf != nil {
+		fmt.Fprintf(tw, "Records (.dbf):\t%d\n", i.Dbf.RecordCount)
+		fmt.Fprintf(tw, "dBase version:\t%v\n", i.Dbf.Version)
+		fmt.Fprintf(tw, "dBase date:\t%v\n", i.Dbf.Date)
+		fmt.Fprintf(tw, "Language driver:\t%v\n", dbf.LanguageDriver(i.Dbf.LanguageDriver))
+
+		if i.Dbf.Cpg != `` {
+			fmt.Fprintf(tw, "Code page (.cpg):\t%v\n", i.Dbf.Cpg)
+		}
+	}
+
+	tw.Flush()
+
+	if i.Dbf != nil {
+		fmt.Fprintf(w, "\nFields (%d):\n", len(i.Dbf.Fields))
+
+		fmt.Fprintf(tw, "  Name\tType\tLength\tDecimals\n")
+		for _, f := range i.Dbf.Fields {
+			fmt.Fprintf(tw, "  %v\t%v\t%d\t%d\n", f.Name, f.Type, f.Length, f.Decimals)
+		}
+
+		tw.Flush()
+	}
+
+	fmt.Fprintf(w, "\nFiles:\n")
+	for _, s := range i.Sidecars {
+		mark := `-`
+		if s.Present {
+			mark = `+`
+		}
+
+		fmt.Fprintf(tw, "  %v .%v\t%v\t%v\n", mark, s.Ext, s.Description, s.Path)
+	}
+
+	for _, p := range i.Other {
+		fmt.Fprintf(tw, "  ? %v\t\t\n", p)
+	}
+
+	tw.Flush()
+}
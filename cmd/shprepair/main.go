@@ -0,0 +1,85 @@
+// shprepair rebuilds .shx from .shp record headers and fixes .shp header file length and
+// bounding box. Everything changed is listed.
+//
+// Usage:
+//
+//	shprepair [-n] [-truncate] [-json] file.shp [file2.shp ...]
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/raspi/GeoESRIShapeFile/repair"
+	"os"
+)
+
+func main() {
+	dryRun := flag.Bool(`n`, false, `dry run, only list what would be changed`)
+	truncate := flag.Bool(`truncate`, false, `cut partial record from end of .shp`)
+	jsonOutput := flag.Bool(`json`, false, `JSON output`)
+
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [-n] [-truncate] [-json] file.shp [file2.shp ...]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	opts := repair.Options{
+		Truncate: *truncate,
+		DryRun:   *dryRun,
+	}
+
+	var results []repair.Result
+	failed := false
+
+	for _, fpath := range flag.Args() {
+		r, err := repair.Dataset(fpath, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v: %v\n", fpath, err)
+			failed = true
+			continue
+		}
+
+		results = append(results, r)
+	}
+
+	if *jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent(``, `  `)
+
+		var err error
+		if len(results) == 1 && flag.NArg() == 1 {
+			err = enc.Encode(results[0])
+		} else {
+			err = enc.Encode(results)
+		}
+
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	} else {
+		for _, r := range results {
+			fmt.Printf("%v: %d records, %d changes\n", r.Path, r.Records, len(r.Changes))
+
+			for _, c := range r.Changes {
+				fmt.Printf("  %v\n", c)
+			}
+
+			for _, w := range r.Warnings {
+				fmt.Printf("  warning: %v\n", w)
+			}
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,101 @@
+// shpvalidate checks ESRI shapefile datasets against the specification and lists findings with
+// severity and byte offsets. Exit status is 1 if any errors were found.
+//
+// Usage:
+//
+//	shpvalidate [-json] [-min warning] file.shp [file2.shp ...]
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/raspi/GeoESRIShapeFile/validate"
+	"os"
+)
+
+func main() {
+	jsonOutput := flag.Bool(`json`, false, `JSON output`)
+	minSeverity := flag.String(`min`, `info`, `minimum severity to list: info, warning or error`)
+
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [-json] [-min warning] file.shp [file2.shp ...]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var min validate.Severity
+	switch *minSeverity {
+	case `info`:
+		min = validate.Info
+	case `warning`:
+		min = validate.Warning
+	case `error`:
+		min = validate.Error
+	default:
+		fmt.Fprintf(os.Stderr, "invalid severity %q\n", *minSeverity)
+		os.Exit(2)
+	}
+
+	var reports []validate.Report
+	failed := false
+
+	for _, fpath := range flag.Args() {
+		r, err := validate.Dataset(fpath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v: %v\n", fpath, err)
+			failed = true
+			continue
+		}
+
+		if r.HasErrors() {
+			failed = true
+		}
+
+		// Drop findings below minimum
+		findings := r.Findings[:0]
+		for _, f := range r.Findings {
+			if f.Severity >= min {
+				findings = append(findings, f)
+			}
+		}
+
+		r.Findings = findings
+		reports = append(reports, r)
+	}
+
+	if *jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent(``, `  `)
+
+		var err error
+		if len(reports) == 1 && flag.NArg() == 1 {
+			err = enc.Encode(reports[0])
+		} else {
+			err = enc.Encode(reports)
+		}
+
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	} else {
+		for _, r := range reports {
+			fmt.Printf("%v: %d records, %d errors, %d warnings\n", r.Path, r.Records, r.Count(validate.Error), r.Count(validate.Warning))
+
+			for _, f := range r.Findings {
+				fmt.Printf("  %v\n", f)
+			}
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
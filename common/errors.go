@@ -7,6 +7,9 @@ import (
 
 var ErrorNotInitialized = errors.New(`not initialized`)
 
+// Seek backwards or from end on stream, see NewStream
+var ErrorNotSeekable = errors.New(`stream can only seek forward`)
+
 type InvalidFileCode struct {
 	Code uint32
 }
@@ -106,57 +106,66 @@ func (h ShapeFileHeader2) Validate() error {
 
 //Read headers shared by .shp and .shx file
 func ReadHeaders(r ReadSeekCloser) (err error) {
-	err = readFirstHeader(r)
+	_, _, err = ParseHeaders(r)
+	return err
+}
+
+// Same as ReadHeaders, but returns decoded headers
+func ParseHeaders(r ReadSeekCloser) (hdr1 ShapeFileHeader1, hdr2 ShapeFileHeader2, err error) {
+	hdr1, err = parseFirstHeader(r)
 	if err != nil {
-		return xerrors.Errorf(`error reading first header (BE) part: %w`, err)
+		return hdr1, hdr2, xerrors.Errorf(`error reading first header (BE) part: %w`, err)
 	}
 
-	err = readSecondHeader(r)
+	hdr2, err = parseSecondHeader(r)
 	if err != nil {
-		return xerrors.Errorf(`error reading second header (LE) part: %w`, err)
+		return hdr1, hdr2, xerrors.Errorf(`error reading second header (LE) part: %w`, err)
 	}
 
 	offset, err := r.Seek(0, io.SeekCurrent)
 	if err != nil {
-		return err
+		return hdr1, hdr2, err
 	}
 
 	if offset != 100 {
-		return fmt.Errorf(`offset is not 100`)
+		return hdr1, hdr2, fmt.Errorf(`offset is not 100`)
 	}
 
-	return nil
+	return hdr1, hdr2, nil
 }
 
 // Read primary header (notice endianness!)
 func readFirstHeader(r ReadSeekCloser) error {
-	var hdr1 ShapeFileHeader1
+	_, err := parseFirstHeader(r)
+	return err
+}
+
+func parseFirstHeader(r ReadSeekCloser) (hdr1 ShapeFileHeader1, err error) {
 	hdr1.Unused[1] = math.MaxInt32 // to detect possible corruption
-	err := binary.Read(r, binary.BigEndian, &hdr1)
+	err = binary.Read(r, binary.BigEndian, &hdr1)
 	if err != nil {
-		return err
+		return hdr1, err
 	}
 
 	err = hdr1.Validate()
 	if err != nil {
-		return err
+		return hdr1, err
 	}
 
-	return nil
+	return hdr1, nil
 }
 
 // Read secondary header (notice endianness!)
-func readSecondHeader(r ReadSeekCloser) error {
-	var hdr2 ShapeFileHeader2
-	err := binary.Read(r, binary.LittleEndian, &hdr2)
+func parseSecondHeader(r ReadSeekCloser) (hdr2 ShapeFileHeader2, err error) {
+	err = binary.Read(r, binary.LittleEndian, &hdr2)
 	if err != nil {
-		return err
+		return hdr2, err
 	}
 
 	err = hdr2.Validate()
 	if err != nil {
-		return err
+		return hdr2, err
 	}
 
-	return nil
+	return hdr2, nil
 }
@@ -0,0 +1,35 @@
+package common
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func FuzzReadHeaders(f *testing.F) {
+	files, err := filepath.Glob(`../_test_files/*.sh[px]`)
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	for _, fpath := range files {
+		b, err := ioutil.ReadFile(fpath)
+		if err != nil {
+			f.Fatal(err)
+		}
+
+		f.Add(b[:100])
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		r, err := NewReadSeekCloser(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		hdr1, _, err := ParseHeaders(r)
+		if err == nil && hdr1.FileCode != HeaderFileCode {
+			t.Fatalf(`invalid file code %d accepted`, hdr1.FileCode)
+		}
+	})
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"github.com/spf13/afero"
 	"io"
+	"io/fs"
+	"io/ioutil"
 	"time"
 )
 
@@ -41,15 +43,67 @@ func NewReadSeekCloser(b []byte) (rsc ReadSeekCloser, err error) {
 	return f, nil
 }
 
+// Read from r of given size. Every Read is one ReadAt call, so reading n bytes at a time reads
+// one range of n bytes from remote storage. Close closes r if it is io.Closer.
+func NewReaderAt(r io.ReaderAt, size int64) ReadSeekCloser {
+	c, _ := r.(io.Closer)
+
+	return &readerAt{
+		SectionReader: io.NewSectionReader(r, 0, size),
+		closer:        c,
+	}
+}
+
+type readerAt struct {
+	*io.SectionReader
+	closer io.Closer
+}
+
+func (r *readerAt) Close() error {
+	if r.closer == nil {
+		return nil
+	}
+
+	return r.closer.Close()
+}
+
+// Open file from disk, reads are cached in memory
 func OpenFile(fpath string) (ReadSeekCloser, error) {
 	base := afero.NewOsFs()
 	layer := afero.NewMemMapFs()
 	ufs := afero.NewCacheOnReadFs(base, layer, 5*time.Minute)
 
-	f, err := ufs.Open(fpath)
+	return OpenAferoFile(ufs, fpath)
+}
+
+// Open file from afero filesystem
+func OpenAferoFile(fsys afero.Fs, fpath string) (ReadSeekCloser, error) {
+	f, err := fsys.Open(fpath)
 	if err != nil {
 		return nil, err
 	}
 
 	return f, nil
 }
+
+// Open file from io/fs filesystem. Files which can't seek, unlike files of os.DirFS and embed.FS,
+// are read to memory.
+func OpenFSFile(fsys fs.FS, name string) (ReadSeekCloser, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if rsc, ok := f.(ReadSeekCloser); ok {
+		return rsc, nil
+	}
+
+	defer f.Close()
+
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewReadSeekCloser(data)
+}
@@ -0,0 +1,102 @@
+package common
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// Logger is implemented by *slog.Logger. Arguments after message are key-value pairs.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// Log levels, same values as in log/slog
+type Level int
+
+const (
+	LevelDebug Level = -4 // Every record read, very verbose
+	LevelInfo  Level = 0  // Files opened
+	LevelWarn  Level = 4  // Recovered problems, such as corrupt records skipped in lenient mode
+	LevelError Level = 8
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return `DEBUG`
+	case LevelInfo:
+		return `INFO`
+	case LevelWarn:
+		return `WARN`
+	case LevelError:
+		return `ERROR`
+	default:
+		return fmt.Sprintf(`LEVEL(%d)`, int(l))
+	}
+}
+
+// StdLogger writes to standard library logger as "LEVEL message key=value ..."
+type StdLogger struct {
+	Logger *log.Logger // nil writes with package level log functions
+	Level  Level       // Minimum level written
+}
+
+func NewStdLogger(l *log.Logger, level Level) *StdLogger {
+	return &StdLogger{Logger: l, Level: level}
+}
+
+func (sl *StdLogger) Debug(msg string, args ...interface{}) {
+	sl.log(LevelDebug, msg, args)
+}
+
+func (sl *StdLogger) Info(msg string, args ...interface{}) {
+	sl.log(LevelInfo, msg, args)
+}
+
+func (sl *StdLogger) Warn(msg string, args ...interface{}) {
+	sl.log(LevelWarn, msg, args)
+}
+
+func (sl *StdLogger) Error(msg string, args ...interface{}) {
+	sl.log(LevelError, msg, args)
+}
+
+func (sl *StdLogger) log(level Level, msg string, args []interface{}) {
+	if level < sl.Level {
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString(level.String())
+	sb.WriteString(` `)
+	sb.WriteString(msg)
+
+	for idx := 0; idx < len(args); idx += 2 {
+		if idx+1 == len(args) {
+			fmt.Fprintf(&sb, ` !BADKEY=%v`, args[idx])
+			break
+		}
+
+		fmt.Fprintf(&sb, ` %v=%v`, args[idx], args[idx+1])
+	}
+
+	if sl.Logger == nil {
+		log.Print(sb.String())
+		return
+	}
+
+	sl.Logger.Print(sb.String())
+}
+
+// Logger for deprecated SetDebug methods: debug level to standard logger, or nil for no logging
+func DebugLogger(flag bool) Logger {
+	if !flag {
+		return nil
+	}
+
+	return NewStdLogger(nil, LevelDebug)
+}
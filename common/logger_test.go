@@ -0,0 +1,21 @@
+package common
+
+import (
+	"bytes"
+	"log"
+	"testing"
+)
+
+func TestStdLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewStdLogger(log.New(&buf, ``, 0), LevelInfo)
+
+	l.Debug(`not written`, `a`, 1)
+	l.Info(`opened`, `path`, `a.shp`, `records`, 3)
+	l.Warn(`odd`, `key`)
+
+	want := "INFO opened path=a.shp records=3\nWARN odd !BADKEY=key\n"
+	if buf.String() != want {
+		t.Fatalf(`got %q, want %q`, buf.String(), want)
+	}
+}
@@ -0,0 +1,21 @@
+package common
+
+// Progress of iteration. Totals are -1 if they are not known.
+type Progress struct {
+	Records      int64 // Records processed so far
+	TotalRecords int64 // From .shx or .dbf header
+	Bytes        int64 // Offset in file after last processed record
+	TotalBytes   int64 // File length from header
+}
+
+// Fraction of bytes processed between 0 and 1, or -1 if total is not known
+func (p Progress) Fraction() float64 {
+	if p.TotalBytes <= 0 {
+		return -1
+	}
+
+	return float64(p.Bytes) / float64(p.TotalBytes)
+}
+
+// Called after every processed record
+type ProgressFunc func(p Progress)
@@ -0,0 +1,59 @@
+package common
+
+import (
+	"io"
+	"io/ioutil"
+)
+
+/*
+ReadSeekCloser reading r in one pass, for stdin, pipes and HTTP bodies. Seek can only move
+forward, which discards data, and returns ErrorNotSeekable for seeking backwards or from end.
+Close closes r if it is io.Closer.
+*/
+func NewStream(r io.Reader) ReadSeekCloser {
+	c, _ := r.(io.Closer)
+
+	return &stream{r: r, closer: c}
+}
+
+type stream struct {
+	r      io.Reader
+	closer io.Closer
+	offset int64
+}
+
+func (s *stream) Read(p []byte) (n int, err error) {
+	n, err = s.r.Read(p)
+	s.offset += int64(n)
+	return n, err
+}
+
+func (s *stream) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += s.offset
+	default:
+		return s.offset, ErrorNotSeekable
+	}
+
+	if offset < s.offset {
+		return s.offset, ErrorNotSeekable
+	}
+
+	n, err := io.CopyN(ioutil.Discard, s.r, offset-s.offset)
+	s.offset += n
+	if err != nil {
+		return s.offset, err
+	}
+
+	return s.offset, nil
+}
+
+func (s *stream) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+
+	return s.closer.Close()
+}
@@ -0,0 +1,9 @@
+package common
+
+import "encoding/binary"
+
+// Big endian length or offset in 16-bit words at start of b, as bytes. Doubled in 64 bits so
+// that values of 2^31 words or more don't wrap around.
+func WordsToBytes(b []byte) int64 {
+	return int64(binary.BigEndian.Uint32(b)) * 2
+}
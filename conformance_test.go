@@ -0,0 +1,252 @@
+package geoesrishapefile
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/raspi/GeoESRIShapeFile/shp"
+	"io"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var updateGolden = flag.Bool(`update`, false, `rewrite golden files in testdata/golden`)
+
+type goldenRecord struct {
+	Number uint32      `json:"number"`
+	Type   string      `json:"type"`
+	Shape  interface{} `json:"shape"` // See goldenValue
+}
+
+type goldenDataset struct {
+	ShapeType string                   `json:"shape_type"`
+	Records   []goldenRecord           `json:"records"`
+	Fields    []string                 `json:"fields"`
+	Rows      []map[string]interface{} `json:"rows"`
+}
+
+// Decode whole dataset to structure which is compared with golden file
+func readGolden(t *testing.T, fpath string) goldenDataset {
+	sf, err := Open(fpath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sf.Close()
+
+	g := goldenDataset{
+		ShapeType: sf.Fshp.Header2.ShapeType.String(),
+		Records:   []goldenRecord{},
+		Rows:      []map[string]interface{}{},
+	}
+
+	for {
+		n, shape, err := sf.Fshp.ReadRecord()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			t.Fatalf(`record #%d: %v`, len(g.Records), err)
+		}
+
+		g.Records = append(g.Records, goldenRecord{
+			Number: n,
+			Type:   strings.TrimPrefix(fmt.Sprintf(`%T`, shape), `shp.`),
+			Shape:  goldenValue(reflect.ValueOf(shape)),
+		})
+	}
+
+	for _, f := range sf.Fdbf.FieldDescriptors {
+		g.Fields = append(g.Fields, fmt.Sprintf(`%v %v(%d,%d)`, f.Name, f.Type, f.Length, f.DecimalCount))
+	}
+
+	for {
+		row, err := sf.Fdbf.ReadRecord()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			t.Fatalf(`row #%d: %v`, len(g.Rows), err)
+		}
+
+		values := make(map[string]interface{}, len(row))
+		for k, v := range row {
+			values[k] = v.Value
+		}
+
+		g.Rows = append(g.Rows, values)
+	}
+
+	if len(g.Rows) != sf.Fdbf.Header.RecordCount {
+		t.Errorf(`read %d rows, header has %d`, len(g.Rows), sf.Fdbf.Header.RecordCount)
+	}
+
+	return g
+}
+
+// JSON friendly copy of decoded shape: points are [X, Y] pairs and NaN and infinity are strings
+func goldenValue(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.Float64:
+		f := v.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return fmt.Sprint(f)
+		}
+
+		return f
+	case reflect.Slice, reflect.Array:
+		l := make([]interface{}, v.Len())
+		for idx := range l {
+			l[idx] = goldenValue(v.Index(idx))
+		}
+
+		return l
+	case reflect.Struct:
+		if p, ok := v.Interface().(shp.Point); ok {
+			return []interface{}{goldenValue(reflect.ValueOf(p.X)), goldenValue(reflect.ValueOf(p.Y))}
+		}
+
+		m := make(map[string]interface{}, v.NumField())
+		for idx := 0; idx < v.NumField(); idx++ {
+			m[v.Type().Field(idx).Name] = goldenValue(v.Field(idx))
+		}
+
+		return m
+	default:
+		return v.Interface()
+	}
+}
+
+// Golden file layout: one record or row per line, so that differences are easy to locate
+func marshalGolden(g goldenDataset) ([]byte, error) {
+	var buf bytes.Buffer
+
+	line := func(prefix string, v interface{}, suffix string) error {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+
+		buf.WriteString(prefix)
+		buf.Write(b)
+		buf.WriteString(suffix + "\n")
+		return nil
+	}
+
+	list := func(name string, l []interface{}, suffix string) error {
+		buf.WriteString(`  "` + name + `": [` + "\n")
+		for idx, v := range l {
+			sep := `,`
+			if idx == len(l)-1 {
+				sep = ``
+			}
+
+			err := line(`    `, v, sep)
+			if err != nil {
+				return err
+			}
+		}
+
+		buf.WriteString(`  ]` + suffix + "\n")
+		return nil
+	}
+
+	buf.WriteString("{\n")
+
+	err := line(`  "shape_type": `, g.ShapeType, `,`)
+	if err != nil {
+		return nil, err
+	}
+
+	err = line(`  "fields": `, g.Fields, `,`)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]interface{}, len(g.Records))
+	for idx, r := range g.Records {
+		records[idx] = r
+	}
+
+	err = list(`records`, records, `,`)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]interface{}, len(g.Rows))
+	for idx, r := range g.Rows {
+		rows[idx] = r
+	}
+
+	err = list(`rows`, rows, ``)
+	if err != nil {
+		return nil, err
+	}
+
+	buf.WriteString("}\n")
+
+	return buf.Bytes(), nil
+}
+
+// Decode every record and row of each dataset in _test_files and compare with testdata/golden.
+// Run with -update to rewrite golden files after intended changes.
+func TestConformance(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join(`_test_files`, `*.shp`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(files) == 0 {
+		t.Fatal(`no test files`)
+	}
+
+	for _, fpath := range files {
+		name := strings.TrimSuffix(filepath.Base(fpath), `.shp`)
+
+		t.Run(name, func(t *testing.T) {
+			got, err := marshalGolden(readGolden(t, fpath))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !json.Valid(got) {
+				t.Fatalf(`invalid JSON: %s`, got)
+			}
+
+			golden := filepath.Join(`testdata`, `golden`, name+`.json`)
+
+			if *updateGolden {
+				err = ioutil.WriteFile(golden, got, 0644)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				return
+			}
+
+			want, err := ioutil.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(got, want) {
+				gotLines := strings.Split(string(got), "\n")
+				wantLines := strings.Split(string(want), "\n")
+
+				for idx := 0; idx < len(gotLines) && idx < len(wantLines); idx++ {
+					if gotLines[idx] != wantLines[idx] {
+						t.Fatalf("%v differs at line %d:\ngot:  %v\nwant: %v", golden, idx+1, gotLines[idx], wantLines[idx])
+					}
+				}
+
+				t.Fatalf(`%v differs, got %d lines, want %d lines`, golden, len(gotLines), len(wantLines))
+			}
+		})
+	}
+}
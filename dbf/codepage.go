@@ -0,0 +1,94 @@
+package dbf
+
+import "fmt"
+
+// Language driver ID (LDID) in header byte 29. Tells which code page the character data is in.
+// Often 0 and then the code page must be known beforehand or read from .cpg file.
+type LanguageDriver uint8
+
+// Code page numbers for most common language drivers used in shapefiles
+var languageDriverCodePages = map[LanguageDriver]int{
+	0x01: 437,   // US MS-DOS
+	0x02: 850,   // International MS-DOS
+	0x03: 1252,  // Windows ANSI
+	0x04: 10000, // Standard Macintosh
+	0x08: 865,   // Danish OEM
+	0x09: 437,   // Dutch OEM
+	0x0a: 850,   // Dutch OEM
+	0x0b: 437,   // Finnish OEM
+	0x0d: 437,   // French OEM
+	0x0e: 850,   // French OEM
+	0x0f: 437,   // German OEM
+	0x10: 850,   // German OEM
+	0x11: 437,   // Italian OEM
+	0x12: 850,   // Italian OEM
+	0x13: 932,   // Japanese Shift-JIS
+	0x14: 850,   // Spanish OEM
+	0x15: 437,   // Swedish OEM
+	0x16: 850,   // Swedish OEM
+	0x17: 865,   // Norwegian OEM
+	0x18: 437,   // Spanish OEM
+	0x19: 437,   // English OEM (Britain)
+	0x1a: 850,   // English OEM (Britain)
+	0x1b: 437,   // English OEM (US)
+	0x1c: 863,   // French OEM (Canada)
+	0x1d: 850,   // French OEM
+	0x1f: 852,   // Czech OEM
+	0x22: 852,   // Hungarian OEM
+	0x23: 852,   // Polish OEM
+	0x24: 860,   // Portuguese OEM
+	0x25: 850,   // Portuguese OEM
+	0x26: 866,   // Russian OEM
+	0x37: 850,   // English OEM (US)
+	0x40: 852,   // Romanian OEM
+	0x4d: 936,   // Chinese GBK (PRC)
+	0x4e: 949,   // Korean (ANSI/OEM)
+	0x4f: 950,   // Chinese Big5 (Taiwan)
+	0x50: 874,   // Thai (ANSI/OEM)
+	0x57: 1252,  // ANSI
+	0x58: 1252,  // Western European ANSI
+	0x59: 1252,  // Spanish ANSI
+	0x64: 852,   // Eastern European MS-DOS
+	0x65: 866,   // Russian MS-DOS
+	0x66: 865,   // Nordic MS-DOS
+	0x67: 861,   // Icelandic MS-DOS
+	0x6a: 737,   // Greek MS-DOS (437G)
+	0x6b: 857,   // Turkish MS-DOS
+	0x6c: 863,   // French-Canadian MS-DOS
+	0x78: 950,   // Taiwan Big 5
+	0x79: 949,   // Hangul (Wansung)
+	0x7a: 936,   // PRC GBK
+	0x7b: 932,   // Japanese Shift-JIS
+	0x7c: 874,   // Thai Windows/MS-DOS
+	0x7d: 1255,  // Hebrew Windows
+	0x7e: 1256,  // Arabic Windows
+	0x86: 737,   // Greek OEM
+	0x87: 852,   // Slovenian OEM
+	0x88: 857,   // Turkish OEM
+	0x96: 10007, // Russian Macintosh
+	0x97: 10029, // Eastern European Macintosh
+	0x98: 10006, // Greek Macintosh
+	0xc8: 1250,  // Eastern European Windows
+	0xc9: 1251,  // Russian Windows
+	0xca: 1254,  // Turkish Windows
+	0xcb: 1253,  // Greek Windows
+	0xcc: 1257,  // Baltic Windows
+}
+
+// Code page number, 0 if not known
+func (ld LanguageDriver) CodePage() int {
+	return languageDriverCodePages[ld]
+}
+
+func (ld LanguageDriver) String() string {
+	if ld == 0 {
+		return `not set`
+	}
+
+	cp := ld.CodePage()
+	if cp == 0 {
+		return fmt.Sprintf(`unknown: 0x%02x`, uint8(ld))
+	}
+
+	return fmt.Sprintf(`0x%02x (code page %d)`, uint8(ld), cp)
+}
@@ -0,0 +1,374 @@
+package dbf
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/raspi/GeoESRIShapeFile/common"
+	"golang.org/x/text/encoding"
+	"golang.org/x/xerrors"
+	"io"
+	"math/bits"
+	"sort"
+	"strconv"
+)
+
+// Bitmap has one bit per row, see Nulls of columns
+type Bitmap []uint64
+
+func newBitmap(n int) Bitmap {
+	return make(Bitmap, (n+63)/64)
+}
+
+// Is bit of i:th row set
+func (b Bitmap) Get(i int) bool {
+	return b[i/64]&(1<<(uint(i)%64)) != 0
+}
+
+func (b Bitmap) set(i int) {
+	b[i/64] |= 1 << (uint(i) % 64)
+}
+
+// Number of set bits
+func (b Bitmap) Count() (n int) {
+	for _, w := range b {
+		n += bits.OnesCount64(w)
+	}
+
+	return n
+}
+
+/*
+Column is a field read for every row with ReadColumns. Index i of values is always i:th row.
+Blank values and deleted rows are null, which have a zero value and their bit set in Nulls.
+*/
+type Column interface {
+	FieldName() string
+	reset(rows int)
+	setNull(row int)
+	parse(row int, data []byte, dec *encoding.Decoder) error
+}
+
+// Field parsed as base 10 integer
+type Int64Column struct {
+	Name   string
+	Values []int64
+	Nulls  Bitmap
+}
+
+// Field parsed as floating point number
+type Float64Column struct {
+	Name   string
+	Values []float64
+	Nulls  Bitmap
+}
+
+// Field as string, decoded with WithEncoding and trimmed same as DefaultConverterToString
+type StringColumn struct {
+	Name   string
+	Values []string
+	Nulls  Bitmap
+}
+
+type ColumnFieldNotFound struct {
+	Name string
+}
+
+func (e *ColumnFieldNotFound) Error() string {
+	return fmt.Sprintf(`column field not found: %v`, e.Name)
+}
+
+type ColumnParseError struct {
+	Field string
+	Row   int // 0-based
+	Value string
+	Err   error
+}
+
+func (e *ColumnParseError) Error() string {
+	return fmt.Sprintf(`column %v row #%d value %q: %v`, e.Field, e.Row, e.Value, e.Err)
+}
+
+func (e *ColumnParseError) Unwrap() error {
+	return e.Err
+}
+
+// Part of row which is read, adjacent fields are read together
+type rowSpan struct {
+	start, end int
+}
+
+/*
+Read given columns for all rows in one pass. Only the deleted flag and bytes of requested fields
+are read from each row, so rows are never decoded to maps. Field options, converters and filter
+are not used. Current position of ReadRecord is kept.
+*/
+func (db *DBaseFile) ReadColumns(cols ...Column) (err error) {
+	if !db.initialized {
+		return common.ErrorNotInitialized
+	}
+
+	offsets := make(map[string]rowSpan, len(db.FieldDescriptors))
+	pos := 1 // Deleted flag
+
+	for _, f := range db.FieldDescriptors {
+		offsets[f.Name] = rowSpan{start: pos, end: pos + f.Length}
+		pos += f.Length
+	}
+
+	if pos > db.Header.RecordSize {
+		return fmt.Errorf(`fields are %d bytes, record size is %d`, pos, db.Header.RecordSize)
+	}
+
+	fields := make([]rowSpan, len(cols))
+	for i, c := range cols {
+		s, ok := offsets[c.FieldName()]
+		if !ok {
+			return &ColumnFieldNotFound{Name: c.FieldName()}
+		}
+
+		fields[i] = s
+	}
+
+	spans := mergeSpans(append([]rowSpan{{start: 0, end: 1}}, fields...))
+
+	rows := db.Header.RecordCount
+	for _, c := range cols {
+		c.reset(rows)
+	}
+
+	// ReadAt of afero memory files, which OpenFile uses, moves position too
+	current, err := db.r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_, serr := db.r.Seek(current, io.SeekStart)
+		if err == nil {
+			err = serr
+		}
+	}()
+
+	readSpan := db.spanReader()
+
+	var dec *encoding.Decoder
+	if db.encoding != nil {
+		dec = db.encoding.NewDecoder()
+	}
+
+	row := make([]byte, db.Header.RecordSize)
+
+	for n := 0; n < rows; n++ {
+		offset := db.offsets.terminatorEnd + int64(n)*int64(db.Header.RecordSize)
+
+		for _, s := range spans {
+			err = readSpan(row[s.start:s.end], offset+int64(s.start))
+			if err != nil {
+				return xerrors.Errorf(`row #%d: %w`, n, err)
+			}
+		}
+
+		switch RecordFirstCharacter(row[0]) {
+		default:
+			return fmt.Errorf(`row #%d: weird first byte: %[2]d %[2]c %[2]v`, n, row[0])
+		case DeletedRecord:
+			for _, c := range cols {
+				c.setNull(n)
+			}
+
+			continue
+		case OkRecord:
+		}
+
+		for i, c := range cols {
+			err = c.parse(n, row[fields[i].start:fields[i].end], dec)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Read exactly len(p) bytes at offset, with ReadAt if possible so that it's one call per span
+func (db *DBaseFile) spanReader() func(p []byte, offset int64) error {
+	if ra, ok := db.r.(io.ReaderAt); ok {
+		return func(p []byte, offset int64) error {
+			n, err := ra.ReadAt(p, offset)
+			if n == len(p) {
+				return nil
+			}
+
+			if err == nil || err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+
+			return err
+		}
+	}
+
+	return func(p []byte, offset int64) error {
+		_, err := db.r.Seek(offset, io.SeekStart)
+		if err != nil {
+			return err
+		}
+
+		_, err = io.ReadFull(db.r, p)
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+
+		return err
+	}
+}
+
+// Sort spans by start and merge overlapping and adjacent ones
+func mergeSpans(spans []rowSpan) (merged []rowSpan) {
+	sort.Slice(spans, func(i, j int) bool {
+		return spans[i].start < spans[j].start
+	})
+
+	for _, s := range spans {
+		if l := len(merged) - 1; l >= 0 && s.start <= merged[l].end {
+			if s.end > merged[l].end {
+				merged[l].end = s.end
+			}
+
+			continue
+		}
+
+		merged = append(merged, s)
+	}
+
+	return merged
+}
+
+// Read single Int64Column, see ReadColumns
+func (db *DBaseFile) ReadInt64Column(name string) (*Int64Column, error) {
+	c := &Int64Column{Name: name}
+
+	err := db.ReadColumns(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Read single Float64Column, see ReadColumns
+func (db *DBaseFile) ReadFloat64Column(name string) (*Float64Column, error) {
+	c := &Float64Column{Name: name}
+
+	err := db.ReadColumns(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Read single StringColumn, see ReadColumns
+func (db *DBaseFile) ReadStringColumn(name string) (*StringColumn, error) {
+	c := &StringColumn{Name: name}
+
+	err := db.ReadColumns(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Numbers are right aligned and padded with spaces, some writers use NUL
+func trimNumber(data []byte) []byte {
+	return bytes.Trim(data, " \x00")
+}
+
+func (c *Int64Column) FieldName() string {
+	return c.Name
+}
+
+func (c *Int64Column) reset(rows int) {
+	c.Values = make([]int64, rows)
+	c.Nulls = newBitmap(rows)
+}
+
+func (c *Int64Column) setNull(row int) {
+	c.Nulls.set(row)
+}
+
+func (c *Int64Column) parse(row int, data []byte, dec *encoding.Decoder) (err error) {
+	s := trimNumber(data)
+	if len(s) == 0 {
+		c.setNull(row)
+		return nil
+	}
+
+	c.Values[row], err = strconv.ParseInt(string(s), 10, 64)
+	if err != nil {
+		return &ColumnParseError{Field: c.Name, Row: row, Value: string(s), Err: err}
+	}
+
+	return nil
+}
+
+func (c *Float64Column) FieldName() string {
+	return c.Name
+}
+
+func (c *Float64Column) reset(rows int) {
+	c.Values = make([]float64, rows)
+	c.Nulls = newBitmap(rows)
+}
+
+func (c *Float64Column) setNull(row int) {
+	c.Nulls.set(row)
+}
+
+func (c *Float64Column) parse(row int, data []byte, dec *encoding.Decoder) (err error) {
+	s := trimNumber(data)
+	if len(s) == 0 {
+		c.setNull(row)
+		return nil
+	}
+
+	c.Values[row], err = strconv.ParseFloat(string(s), 64)
+	if err != nil {
+		return &ColumnParseError{Field: c.Name, Row: row, Value: string(s), Err: err}
+	}
+
+	return nil
+}
+
+func (c *StringColumn) FieldName() string {
+	return c.Name
+}
+
+func (c *StringColumn) reset(rows int) {
+	c.Values = make([]string, rows)
+	c.Nulls = newBitmap(rows)
+}
+
+func (c *StringColumn) setNull(row int) {
+	c.Nulls.set(row)
+}
+
+func (c *StringColumn) parse(row int, data []byte, dec *encoding.Decoder) (err error) {
+	s := bytes.Trim(data, ` `)
+	if len(s) == 0 {
+		c.setNull(row)
+		return nil
+	}
+
+	if dec != nil {
+		s, err = dec.Bytes(s)
+		if err != nil {
+			return &ColumnParseError{Field: c.Name, Row: row, Value: string(data), Err: err}
+		}
+	}
+
+	c.Values[row] = string(s)
+
+	return nil
+}
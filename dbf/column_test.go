@@ -0,0 +1,166 @@
+package dbf
+
+import (
+	"fmt"
+	"golang.org/x/text/encoding/charmap"
+	"io"
+	"os"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+func writeColumnRows(t testing.TB) string {
+	return writeTestRows(t, [][]byte{
+		[]byte(" K\xe4rki 001"),
+		[]byte("*\xc5land 002"), // Deleted
+		[]byte(" Turku    "),
+		[]byte("       -12"),
+	})
+}
+
+func TestReadColumns(t *testing.T) {
+	db := openInitialized(t, writeColumnRows(t), WithEncoding(charmap.ISO8859_1))
+	defer db.Close()
+
+	name := &StringColumn{Name: `NAME`}
+	code := &Int64Column{Name: `CODE`}
+
+	err := db.ReadColumns(code, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{`Kärki`, ``, `Turku`, ``}; !reflect.DeepEqual(name.Values, want) {
+		t.Errorf(`NAME is %q, should be %q`, name.Values, want)
+	}
+
+	if want := []int64{1, 0, 0, -12}; !reflect.DeepEqual(code.Values, want) {
+		t.Errorf(`CODE is %v, should be %v`, code.Values, want)
+	}
+
+	for i, want := range []bool{false, true, false, true} {
+		if name.Nulls.Get(i) != want {
+			t.Errorf(`NAME row #%d null is %v, should be %v`, i, name.Nulls.Get(i), want)
+		}
+	}
+
+	for i, want := range []bool{false, true, true, false} {
+		if code.Nulls.Get(i) != want {
+			t.Errorf(`CODE row #%d null is %v, should be %v`, i, code.Nulls.Get(i), want)
+		}
+	}
+
+	if code.Nulls.Count() != 2 {
+		t.Errorf(`CODE has %d nulls, should be 2`, code.Nulls.Count())
+	}
+
+	// Position is kept
+	row, err := db.ReadRecord()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if row[`CODE`].Value != `001` {
+		t.Errorf(`ReadRecord after ReadColumns read %v`, row)
+	}
+}
+
+func TestReadFloat64Column(t *testing.T) {
+	db := openInitialized(t, writeColumnRows(t))
+	defer db.Close()
+
+	code, err := db.ReadFloat64Column(`CODE`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []float64{1, 0, 0, -12}; !reflect.DeepEqual(code.Values, want) {
+		t.Errorf(`CODE is %v, should be %v`, code.Values, want)
+	}
+}
+
+func TestReadColumnsErrors(t *testing.T) {
+	db := openInitialized(t, writeColumnRows(t))
+	defer db.Close()
+
+	_, err := db.ReadInt64Column(`MISSING`)
+	if _, ok := err.(*ColumnFieldNotFound); !ok {
+		t.Errorf(`expected *ColumnFieldNotFound, got %v`, err)
+	}
+
+	_, err = db.ReadInt64Column(`NAME`)
+	if perr, ok := err.(*ColumnParseError); !ok || perr.Row != 0 {
+		t.Errorf(`expected *ColumnParseError for row #0, got %v`, err)
+	}
+}
+
+// Counts bytes read with ReadAt
+type countingReaderAt struct {
+	r     io.ReaderAt
+	bytes int64
+}
+
+func (c *countingReaderAt) ReadAt(p []byte, off int64) (n int, err error) {
+	n, err = c.r.ReadAt(p, off)
+	atomic.AddInt64(&c.bytes, int64(n))
+	return n, err
+}
+
+func TestReadColumnsReadsOnlyFields(t *testing.T) {
+	f, err := os.Open(writeColumnRows(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cr := &countingReaderAt{r: f}
+	db := OpenReaderAt(cr, fi.Size())
+
+	err = db.Initialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	before := cr.bytes
+
+	_, err = db.ReadInt64Column(`CODE`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Deleted flag and CODE of each row
+	if read := cr.bytes - before; read != 4*(1+3) {
+		t.Errorf(`read %d bytes, should be %d`, read, 4*(1+3))
+	}
+}
+
+func BenchmarkReadInt64Column(b *testing.B) {
+	rows := make([][]byte, 10000)
+	for i := range rows {
+		rows[i] = []byte(fmt.Sprintf(" ROW   %03d", i%1000))
+	}
+
+	db := openInitialized(b, writeTestRows(b, rows))
+	defer db.Close()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		code, err := db.ReadInt64Column(`CODE`)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		sum := int64(0)
+		for _, v := range code.Values {
+			sum += v
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package dbf
+
+import (
+	"golang.org/x/text/encoding"
+	"io"
+)
+
+/*
+ConcurrentReader reads rows with ReadAt only, so it can be used from several goroutines at the
+same time. Options and filter are the same as for DBaseFile.
+*/
+type ConcurrentReader struct {
+	Header           Header
+	FieldDescriptors []FieldDescriptor
+	db               DBaseFile // Header and options, its reader is not used after initializing
+	r                io.ReaderAt
+	size             int64
+}
+
+// Read header from r of given size, for example *os.File
+func NewConcurrentReader(r io.ReaderAt, size int64, opts ...Option) (cr *ConcurrentReader, err error) {
+	db := OpenReaderAt(r, size, opts...)
+
+	err = db.Initialize()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ConcurrentReader{
+		Header:           db.Header,
+		FieldDescriptors: db.FieldDescriptors,
+		db:               db,
+		r:                r,
+		size:             size,
+	}, nil
+}
+
+// Set filter, see DBaseFile SetFilter. Not safe to call while reading.
+func (cr *ConcurrentReader) SetFilter(f *Filter) error {
+	return cr.db.SetFilter(f)
+}
+
+// Read n:th (0-based) row. Returns io.EOF past last row, and ErrorDeletedRecord and ErrorFilteredRecord
+// same as DBaseFile ReadRecord.
+func (cr *ConcurrentReader) ReadRecordAt(n int) (m map[string]Record, err error) {
+	if n < 0 || n >= cr.Header.RecordCount {
+		return nil, io.EOF
+	}
+
+	rawalldata := make([]byte, cr.Header.RecordSize)
+
+	rBytes, err := cr.r.ReadAt(rawalldata, cr.db.offsets.terminatorEnd+int64(n)*int64(cr.Header.RecordSize))
+	if rBytes != len(rawalldata) {
+		if err == nil || err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+
+		return nil, err
+	}
+
+	var dec *encoding.Decoder
+	if cr.db.encoding != nil {
+		// Decoders keep state, so every row gets its own
+		dec = cr.db.encoding.NewDecoder()
+	}
+
+	m = make(map[string]Record, cr.Header.FieldCount)
+
+	err = cr.db.decodeRecord(rawalldata, dec, m)
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
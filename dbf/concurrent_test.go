@@ -0,0 +1,61 @@
+package dbf
+
+import (
+	"golang.org/x/text/encoding/charmap"
+	"io"
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestConcurrentReader(t *testing.T) {
+	fpath := writeTestFile(t)
+	want := readAll(t, fpath, WithEncoding(charmap.ISO8859_1))
+
+	f, err := os.Open(fpath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cr, err := NewConcurrentReader(f, fi.Size(), WithEncoding(charmap.ISO8859_1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := 0; i < 100; i++ {
+				n := i % len(want)
+
+				row, err := cr.ReadRecordAt(n)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+
+				if !reflect.DeepEqual(row, want[n]) {
+					t.Errorf(`row #%d is %v, should be %v`, n, row, want[n])
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	_, err = cr.ReadRecordAt(len(want))
+	if err != io.EOF {
+		t.Fatalf(`expected io.EOF past last row, got %v`, err)
+	}
+}
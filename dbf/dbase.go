@@ -3,9 +3,9 @@ package dbf
 import (
 	"fmt"
 	"github.com/raspi/GeoESRIShapeFile/common"
+	"golang.org/x/text/encoding"
 	"golang.org/x/xerrors"
 	"io"
-	"log"
 )
 
 type Operation uint8
@@ -21,12 +21,17 @@ type DBaseFile struct {
 	FieldDescriptors             []FieldDescriptor
 	converterFunctions           map[string]ConverterFunction
 	r                            common.ReadSeekCloser
-	debug                        bool
+	logger                       common.Logger // nil for no logging
 	initialized                  bool
 	useDefaultConverterIfMissing bool
 	defaultConverter             ConverterFunction
 	parseFieldNames              []string
 	parseFieldNamesOperation     Operation
+	filter                       *Filter
+	encoding                     encoding.Encoding // nil passes character data as-is
+	decoder                      *encoding.Decoder
+	iterErr                      error  // Error which stopped Rows iterator, see Err
+	buf                          []byte // Row buffer reused by ReadRecordInto
 
 	offsets struct {
 		mainHeaderEnd int64 // 32
@@ -36,6 +41,11 @@ type DBaseFile struct {
 }
 
 func (db *DBaseFile) Close() error {
+	if db == nil || db.r == nil {
+		// Not opened
+		return nil
+	}
+
 	return db.r.Close()
 }
 
@@ -43,23 +53,16 @@ func (db DBaseFile) Offset() (int64, error) {
 	return db.r.Seek(0, io.SeekCurrent)
 }
 
-// See datatypes.go for notes about character encoding
+// See Open for options based API
 func New(fname string, parseFieldNames []string, parseFieldNamesOperation Operation, defaultConverter ConverterFunction, converters map[string]ConverterFunction) (db DBaseFile, err error) {
-	f, err := common.OpenFile(fname)
+	db, err = Open(fname, WithDefaultConverter(defaultConverter))
 	if err != nil {
 		return db, err
 	}
 
-	db = DBaseFile{
-		converterFunctions:           converters,
-		r:                            f,
-		debug:                        false,
-		useDefaultConverterIfMissing: true,
-		defaultConverter:             defaultConverter,
-		parseFieldNames:              parseFieldNames,
-		parseFieldNamesOperation:     parseFieldNamesOperation,
-		initialized:                  false,
-	}
+	db.converterFunctions = converters
+	db.parseFieldNames = parseFieldNames
+	db.parseFieldNamesOperation = parseFieldNamesOperation
 
 	return db, nil
 }
@@ -84,8 +87,8 @@ func (db *DBaseFile) Initialize() (err error) {
 		return fmt.Errorf(`fields found %v but should be %v`, len(db.FieldDescriptors), db.Header.FieldCount)
 	}
 
-	if db.debug {
-		log.Printf(`header read successfully`)
+	if db.logger != nil {
+		db.logger.Debug(`header read successfully`, `records`, db.Header.RecordCount, `fields`, len(db.FieldDescriptors))
 	}
 
 	db.initialized = true
@@ -106,10 +109,46 @@ func (db *DBaseFile) checkOffset(expected int64, estr string) error {
 	return nil
 }
 
+// Set logger, for example *slog.Logger. nil disables logging, which is the default.
+func (db *DBaseFile) SetLogger(l common.Logger) {
+	db.logger = l
+}
+
+func (db *DBaseFile) GetLogger() common.Logger {
+	return db.logger
+}
+
+// Deprecated: use SetLogger. true logs debug messages with standard log package.
 func (db *DBaseFile) SetDebug(flag bool) {
-	db.debug = flag
+	db.logger = common.DebugLogger(flag)
 }
 
 func (db *DBaseFile) GetDebug() bool {
-	return db.debug
+	return db.logger != nil
+}
+
+// Set row filter for ReadRecord. Rows not matching the filter return ErrorFilteredRecord, same as
+// deleted rows, so that caller can skip the matching geometry. Fields used in filter are checked
+// against FieldDescriptors so this must be called after Initialize. Use nil to remove filter.
+func (db *DBaseFile) SetFilter(f *Filter) error {
+	if f == nil {
+		db.filter = nil
+		return nil
+	}
+
+	if !db.initialized {
+		return common.ErrorNotInitialized
+	}
+
+	err := db.checkFilterFields(f)
+	if err != nil {
+		return err
+	}
+
+	db.filter = f
+	return nil
+}
+
+func (db *DBaseFile) GetFilter() *Filter {
+	return db.filter
 }
@@ -0,0 +1,949 @@
+package dbf
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+/*
+Filter is a small expression language for skipping rows, for example:
+
+	POP > 1000 AND NAME LIKE 'Hel%'
+	NOT (TYPE IN ('A', 'B') OR CODE IS NULL)
+
+Supported:
+  - Comparison: = != <> < <= > >=
+  - Pattern: LIKE, NOT LIKE with % (any characters) and _ (single character)
+  - Null check: IS NULL, IS NOT NULL
+  - List: IN (...), NOT IN (...)
+  - Logical: AND, OR, NOT and parentheses
+  - Literals: numbers, 'strings' (quote is escaped by doubling it), TRUE, FALSE
+  - Fields: NAME or "NAME" (case sensitive, same as FieldDescriptor.Name)
+
+Values are compared after converters have been run. Numeric strings are compared as numbers
+against numeric literals. Integers are compared as integers, so int64 values keep their precision.
+
+NULL (nil) values follow SQL three-valued logic: comparison, LIKE and IN with NULL are unknown,
+NOT unknown is unknown, and a row matches only if the whole expression is true. For example
+both CODE = 1 and NOT (CODE = 1) skip rows where CODE is NULL. Use IS NULL to match them.
+*/
+type Filter struct {
+	expression string
+	root       boolNode
+	fields     []string
+}
+
+var ErrorFilteredRecord = errors.New("filtered record")
+
+type FilterSyntaxError struct {
+	Position int
+	Message  string
+}
+
+func (e *FilterSyntaxError) Error() string {
+	return fmt.Sprintf(`filter syntax error at position %d: %s`, e.Position, e.Message)
+}
+
+type FilterFieldNotFound struct {
+	Name string
+}
+
+func (e *FilterFieldNotFound) Error() string {
+	return fmt.Sprintf(`filter field not found: %v`, e.Name)
+}
+
+type FilterTypeMismatch struct {
+	Field string
+	Value interface{}
+	Want  string
+}
+
+func (e *FilterTypeMismatch) Error() string {
+	return fmt.Sprintf(`filter field %v value %#v is not %v`, e.Field, e.Value, e.Want)
+}
+
+// Parse filter expression
+func ParseFilter(expression string) (f *Filter, err error) {
+	tokens, err := tokenizeFilter(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	p := filterParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.peek().kind != tokenEnd {
+		return nil, &FilterSyntaxError{Position: p.peek().pos, Message: fmt.Sprintf(`unexpected %q`, p.peek().text)}
+	}
+
+	return &Filter{
+		expression: expression,
+		root:       root,
+		fields:     p.fields,
+	}, nil
+}
+
+func (f Filter) String() string {
+	return f.expression
+}
+
+// Field names referenced in expression
+func (f Filter) Fields() []string {
+	return f.fields
+}
+
+// Match decoded record against filter. Unknown result (see NULL above) doesn't match.
+func (f Filter) Match(m map[string]Record) (bool, error) {
+	t, err := f.root.match(m)
+	return t == truthTrue, err
+}
+
+// Check that all fields used in filter exist and will be decoded
+func (db *DBaseFile) checkFilterFields(f *Filter) error {
+	for _, name := range f.Fields() {
+		found := false
+		for _, fd := range db.FieldDescriptors {
+			if fd.Name == name {
+				found = true
+				break
+			}
+		}
+
+		if !found || db.isSkippedField(name) {
+			return &FilterFieldNotFound{Name: name}
+		}
+	}
+
+	return nil
+}
+
+/*
+Lexer
+*/
+
+type tokenKind uint8
+
+const (
+	tokenEnd tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenOperator
+	tokenKeyword
+	tokenLParen
+	tokenRParen
+	tokenComma
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+var filterKeywords = map[string]bool{
+	`AND`: true, `OR`: true, `NOT`: true, `LIKE`: true, `IS`: true, `NULL`: true, `IN`: true, `TRUE`: true, `FALSE`: true,
+}
+
+func tokenizeFilter(s string) (tokens []token, err error) {
+	rs := []rune(s)
+	i := 0
+
+	for i < len(rs) {
+		c := rs[i]
+		start := i
+
+		switch {
+		case unicode.IsSpace(c):
+			i++
+			continue
+		case c == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: `(`, pos: start})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: `)`, pos: start})
+			i++
+		case c == ',':
+			tokens = append(tokens, token{kind: tokenComma, text: `,`, pos: start})
+			i++
+		case c == '=' || c == '<' || c == '>' || c == '!':
+			i++
+			if i < len(rs) && (rs[i] == '=' || (c == '<' && rs[i] == '>')) {
+				i++
+			}
+
+			op := string(rs[start:i])
+			if op == `!` {
+				return nil, &FilterSyntaxError{Position: start, Message: `expected != operator`}
+			}
+
+			tokens = append(tokens, token{kind: tokenOperator, text: op, pos: start})
+		case c == '\'' || c == '"':
+			// String literal or quoted field name, quote is escaped by doubling it
+			var sb strings.Builder
+			i++
+			closed := false
+
+			for i < len(rs) {
+				if rs[i] == c {
+					if i+1 < len(rs) && rs[i+1] == c {
+						sb.WriteRune(c)
+						i += 2
+						continue
+					}
+
+					closed = true
+					i++
+					break
+				}
+
+				sb.WriteRune(rs[i])
+				i++
+			}
+
+			if !closed {
+				return nil, &FilterSyntaxError{Position: start, Message: `unterminated quote`}
+			}
+
+			kind := tokenString
+			if c == '"' {
+				kind = tokenIdent
+			}
+
+			tokens = append(tokens, token{kind: kind, text: sb.String(), pos: start})
+		case unicode.IsDigit(c) || c == '.' || c == '-' || c == '+':
+			i++
+			for i < len(rs) && (unicode.IsDigit(rs[i]) || rs[i] == '.' || rs[i] == 'e' || rs[i] == 'E' ||
+				((rs[i] == '-' || rs[i] == '+') && (rs[i-1] == 'e' || rs[i-1] == 'E'))) {
+				i++
+			}
+
+			txt := string(rs[start:i])
+			_, err = strconv.ParseFloat(txt, 64)
+			if err != nil {
+				return nil, &FilterSyntaxError{Position: start, Message: fmt.Sprintf(`invalid number %q`, txt)}
+			}
+
+			tokens = append(tokens, token{kind: tokenNumber, text: txt, pos: start})
+		case unicode.IsLetter(c) || c == '_':
+			for i < len(rs) && (unicode.IsLetter(rs[i]) || unicode.IsDigit(rs[i]) || rs[i] == '_') {
+				i++
+			}
+
+			txt := string(rs[start:i])
+			if filterKeywords[strings.ToUpper(txt)] {
+				tokens = append(tokens, token{kind: tokenKeyword, text: strings.ToUpper(txt), pos: start})
+			} else {
+				tokens = append(tokens, token{kind: tokenIdent, text: txt, pos: start})
+			}
+		default:
+			return nil, &FilterSyntaxError{Position: start, Message: fmt.Sprintf(`unexpected character %q`, c)}
+		}
+	}
+
+	tokens = append(tokens, token{kind: tokenEnd, pos: len(rs)})
+
+	return tokens, nil
+}
+
+/*
+Parser
+
+	or      := and { OR and }
+	and     := not { AND not }
+	not     := NOT not | primary
+	primary := '(' or ')' | value ( op value | [NOT] LIKE string | IS [NOT] NULL | [NOT] IN '(' value { ',' value } ')' )
+	value   := field | number | string | TRUE | FALSE
+*/
+
+type filterParser struct {
+	tokens []token
+	pos    int
+	fields []string
+}
+
+func (p *filterParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *filterParser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEnd {
+		p.pos++
+	}
+
+	return t
+}
+
+func (p *filterParser) isKeyword(kw string) bool {
+	t := p.peek()
+	return t.kind == tokenKeyword && t.text == kw
+}
+
+func (p *filterParser) expect(kind tokenKind, what string) (token, error) {
+	t := p.next()
+	if t.kind != kind {
+		return t, &FilterSyntaxError{Position: t.pos, Message: fmt.Sprintf(`expected %v, got %q`, what, t.text)}
+	}
+
+	return t, nil
+}
+
+func (p *filterParser) parseOr() (boolNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isKeyword(`OR`) {
+		p.next()
+
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		left = orNode{left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (boolNode, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isKeyword(`AND`) {
+		p.next()
+
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		left = andNode{left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *filterParser) parseNot() (boolNode, error) {
+	if p.isKeyword(`NOT`) {
+		p.next()
+
+		n, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		return notNode{n: n}, nil
+	}
+
+	return p.parsePrimary()
+}
+
+func (p *filterParser) parsePrimary() (boolNode, error) {
+	if p.peek().kind == tokenLParen {
+		p.next()
+
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = p.expect(tokenRParen, `)`)
+		if err != nil {
+			return nil, err
+		}
+
+		return n, nil
+	}
+
+	left, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+
+	t := p.peek()
+
+	if t.kind == tokenOperator {
+		p.next()
+
+		right, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+
+		op := t.text
+		if op == `<>` {
+			op = `!=`
+		}
+
+		return compareNode{op: op, left: left, right: right}, nil
+	}
+
+	if t.kind != tokenKeyword {
+		return nil, &FilterSyntaxError{Position: t.pos, Message: fmt.Sprintf(`expected comparison, got %q`, t.text)}
+	}
+
+	switch t.text {
+	case `IS`:
+		p.next()
+
+		negate := false
+		if p.isKeyword(`NOT`) {
+			p.next()
+			negate = true
+		}
+
+		if !p.isKeyword(`NULL`) {
+			return nil, &FilterSyntaxError{Position: p.peek().pos, Message: `expected NULL`}
+		}
+		p.next()
+
+		var n boolNode = isNullNode{v: left}
+		if negate {
+			n = notNode{n: n}
+		}
+
+		return n, nil
+	case `NOT`, `LIKE`, `IN`:
+		negate := false
+		if t.text == `NOT` {
+			p.next()
+			negate = true
+		}
+
+		var n boolNode
+
+		switch {
+		case p.isKeyword(`LIKE`):
+			p.next()
+
+			pattern, err := p.expect(tokenString, `pattern string`)
+			if err != nil {
+				return nil, err
+			}
+
+			n = likeNode{v: left, re: likeToRegexp(pattern.text)}
+		case p.isKeyword(`IN`):
+			p.next()
+
+			_, err = p.expect(tokenLParen, `(`)
+			if err != nil {
+				return nil, err
+			}
+
+			var list []valueNode
+			for {
+				v, err := p.parseValue()
+				if err != nil {
+					return nil, err
+				}
+
+				list = append(list, v)
+
+				if p.peek().kind != tokenComma {
+					break
+				}
+				p.next()
+			}
+
+			_, err = p.expect(tokenRParen, `)`)
+			if err != nil {
+				return nil, err
+			}
+
+			n = inNode{v: left, list: list}
+		default:
+			return nil, &FilterSyntaxError{Position: p.peek().pos, Message: `expected LIKE or IN`}
+		}
+
+		if negate {
+			n = notNode{n: n}
+		}
+
+		return n, nil
+	default:
+		return nil, &FilterSyntaxError{Position: t.pos, Message: fmt.Sprintf(`unexpected %v`, t.text)}
+	}
+}
+
+func (p *filterParser) parseValue() (valueNode, error) {
+	t := p.next()
+
+	switch t.kind {
+	case tokenIdent:
+		p.addField(t.text)
+		return fieldNode{field: t.text}, nil
+	case tokenNumber:
+		if i, err := strconv.ParseInt(t.text, 10, 64); err == nil {
+			return literalNode{v: i}, nil
+		}
+
+		f, _ := strconv.ParseFloat(t.text, 64)
+		return literalNode{v: f}, nil
+	case tokenString:
+		return literalNode{v: t.text}, nil
+	case tokenKeyword:
+		switch t.text {
+		case `TRUE`:
+			return literalNode{v: true}, nil
+		case `FALSE`:
+			return literalNode{v: false}, nil
+		}
+	}
+
+	return nil, &FilterSyntaxError{Position: t.pos, Message: fmt.Sprintf(`expected field or value, got %q`, t.text)}
+}
+
+func (p *filterParser) addField(name string) {
+	for _, f := range p.fields {
+		if f == name {
+			return
+		}
+	}
+
+	p.fields = append(p.fields, name)
+}
+
+// Convert LIKE pattern to anchored regular expression
+func likeToRegexp(pattern string) *regexp.Regexp {
+	var sb strings.Builder
+	sb.WriteString(`^(?s:`)
+
+	for _, c := range pattern {
+		switch c {
+		case '%':
+			sb.WriteString(`.*`)
+		case '_':
+			sb.WriteString(`.`)
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	sb.WriteString(`)$`)
+
+	return regexp.MustCompile(sb.String())
+}
+
+/*
+Evaluation
+*/
+
+// Result of boolean node, SQL three-valued logic
+type truth uint8
+
+const (
+	truthFalse truth = iota
+	truthTrue
+	truthUnknown // Comparison with NULL
+)
+
+func truthOf(b bool) truth {
+	if b {
+		return truthTrue
+	}
+
+	return truthFalse
+}
+
+type boolNode interface {
+	match(m map[string]Record) (truth, error)
+}
+
+type valueNode interface {
+	value(m map[string]Record) (interface{}, error)
+	name() string
+}
+
+type orNode struct {
+	left, right boolNode
+}
+
+func (n orNode) match(m map[string]Record) (truth, error) {
+	l, err := n.left.match(m)
+	if err != nil || l == truthTrue {
+		return l, err
+	}
+
+	r, err := n.right.match(m)
+	if err != nil || r == truthTrue {
+		return r, err
+	}
+
+	if l == truthUnknown || r == truthUnknown {
+		return truthUnknown, nil
+	}
+
+	return truthFalse, nil
+}
+
+type andNode struct {
+	left, right boolNode
+}
+
+func (n andNode) match(m map[string]Record) (truth, error) {
+	l, err := n.left.match(m)
+	if err != nil || l == truthFalse {
+		return l, err
+	}
+
+	r, err := n.right.match(m)
+	if err != nil || r == truthFalse {
+		return r, err
+	}
+
+	if l == truthUnknown || r == truthUnknown {
+		return truthUnknown, nil
+	}
+
+	return truthTrue, nil
+}
+
+type notNode struct {
+	n boolNode
+}
+
+func (n notNode) match(m map[string]Record) (truth, error) {
+	t, err := n.n.match(m)
+
+	switch t {
+	case truthTrue:
+		return truthFalse, err
+	case truthFalse:
+		return truthTrue, err
+	default:
+		return truthUnknown, err
+	}
+}
+
+type isNullNode struct {
+	v valueNode
+}
+
+func (n isNullNode) match(m map[string]Record) (truth, error) {
+	v, err := n.v.value(m)
+	return truthOf(v == nil), err
+}
+
+type likeNode struct {
+	v  valueNode
+	re *regexp.Regexp
+}
+
+func (n likeNode) match(m map[string]Record) (truth, error) {
+	v, err := n.v.value(m)
+	if err != nil {
+		return truthFalse, err
+	}
+
+	if v == nil {
+		return truthUnknown, nil
+	}
+
+	s, ok := toString(v)
+	if !ok {
+		return truthFalse, &FilterTypeMismatch{Field: n.v.name(), Value: v, Want: `string`}
+	}
+
+	return truthOf(n.re.MatchString(s)), nil
+}
+
+type inNode struct {
+	v    valueNode
+	list []valueNode
+}
+
+// True if any item is equal, otherwise unknown if value or any item is NULL
+func (n inNode) match(m map[string]Record) (truth, error) {
+	result := truthFalse
+
+	for _, item := range n.list {
+		t, err := compareNode{op: `=`, left: n.v, right: item}.match(m)
+		if err != nil || t == truthTrue {
+			return t, err
+		}
+
+		if t == truthUnknown {
+			result = truthUnknown
+		}
+	}
+
+	return result, nil
+}
+
+type compareNode struct {
+	op          string
+	left, right valueNode
+}
+
+func (n compareNode) match(m map[string]Record) (truth, error) {
+	lv, err := n.left.value(m)
+	if err != nil {
+		return truthFalse, err
+	}
+
+	rv, err := n.right.value(m)
+	if err != nil {
+		return truthFalse, err
+	}
+
+	if lv == nil || rv == nil {
+		return truthUnknown, nil
+	}
+
+	var c int
+
+	_, lnum := nativeNumber(lv)
+	_, rnum := nativeNumber(rv)
+	lb, lbool := lv.(bool)
+	rb, rbool := rv.(bool)
+
+	switch {
+	case lbool || rbool:
+		if !lbool || !rbool {
+			return truthFalse, n.mismatch(lv, rv, `bool`)
+		}
+
+		if n.op != `=` && n.op != `!=` {
+			return truthFalse, n.mismatch(lv, rv, `comparable`)
+		}
+
+		return truthOf((lb == rb) == (n.op == `=`)), nil
+	case lnum || rnum:
+		// Compare as numbers, numeric strings are converted
+		ln, ok := toNumber(lv)
+		if !ok {
+			return truthFalse, n.mismatch(lv, rv, `number`)
+		}
+
+		rn, ok := toNumber(rv)
+		if !ok {
+			return truthFalse, n.mismatch(lv, rv, `number`)
+		}
+
+		if ln.isNaN() || rn.isNaN() {
+			// NaN isn't equal, less or greater than anything, same as NULL
+			return truthUnknown, nil
+		}
+
+		c = compareNumbers(ln, rn)
+	default:
+		ls, ok := toString(lv)
+		if !ok {
+			return truthFalse, n.mismatch(lv, rv, `string`)
+		}
+
+		rs, ok := toString(rv)
+		if !ok {
+			return truthFalse, n.mismatch(lv, rv, `string`)
+		}
+
+		c = strings.Compare(ls, rs)
+	}
+
+	switch n.op {
+	case `=`:
+		return truthOf(c == 0), nil
+	case `!=`:
+		return truthOf(c != 0), nil
+	case `<`:
+		return truthOf(c < 0), nil
+	case `<=`:
+		return truthOf(c <= 0), nil
+	case `>`:
+		return truthOf(c > 0), nil
+	case `>=`:
+		return truthOf(c >= 0), nil
+	default:
+		return truthFalse, fmt.Errorf(`unknown operator: %v`, n.op)
+	}
+}
+
+func (n compareNode) mismatch(lv, rv interface{}, want string) error {
+	if n.left.name() != `` {
+		return &FilterTypeMismatch{Field: n.left.name(), Value: lv, Want: want}
+	}
+
+	return &FilterTypeMismatch{Field: n.right.name(), Value: rv, Want: want}
+}
+
+type fieldNode struct {
+	field string
+}
+
+func (n fieldNode) value(m map[string]Record) (interface{}, error) {
+	rec, ok := m[n.field]
+	if !ok {
+		return nil, &FilterFieldNotFound{Name: n.field}
+	}
+
+	return rec.Value, nil
+}
+
+func (n fieldNode) name() string {
+	return n.field
+}
+
+type literalNode struct {
+	v interface{}
+}
+
+func (n literalNode) value(m map[string]Record) (interface{}, error) {
+	return n.v, nil
+}
+
+func (n literalNode) name() string {
+	return ``
+}
+
+// Integer or floating point number, integers are kept as int64 so that they don't lose precision
+type number struct {
+	i     int64
+	f     float64
+	isInt bool
+}
+
+func (n number) isNaN() bool {
+	return !n.isInt && math.IsNaN(n.f)
+}
+
+// Is value a native number
+func nativeNumber(v interface{}) (number, bool) {
+	switch n := v.(type) {
+	case float64:
+		return number{f: n}, true
+	case float32:
+		return number{f: float64(n)}, true
+	case int:
+		return number{i: int64(n), isInt: true}, true
+	case int8:
+		return number{i: int64(n), isInt: true}, true
+	case int16:
+		return number{i: int64(n), isInt: true}, true
+	case int32:
+		return number{i: int64(n), isInt: true}, true
+	case int64:
+		return number{i: n, isInt: true}, true
+	case uint8:
+		return number{i: int64(n), isInt: true}, true
+	case uint16:
+		return number{i: int64(n), isInt: true}, true
+	case uint32:
+		return number{i: int64(n), isInt: true}, true
+	case uint:
+		return unsignedNumber(uint64(n)), true
+	case uint64:
+		return unsignedNumber(n), true
+	default:
+		return number{}, false
+	}
+}
+
+func unsignedNumber(n uint64) number {
+	if n > math.MaxInt64 {
+		return number{f: float64(n)}
+	}
+
+	return number{i: int64(n), isInt: true}
+}
+
+// Native number or numeric string
+func toNumber(v interface{}) (number, bool) {
+	if n, ok := nativeNumber(v); ok {
+		return n, true
+	}
+
+	s, ok := toString(v)
+	if !ok {
+		return number{}, false
+	}
+
+	s = strings.TrimSpace(s)
+
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return number{i: i, isInt: true}, true
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	return number{f: f}, err == nil
+}
+
+// Compare numbers which are not NaN, -1 if a < b, 1 if a > b and 0 otherwise
+func compareNumbers(a, b number) int {
+	switch {
+	case a.isInt && b.isInt:
+		return compareInts(a.i, b.i)
+	case a.isInt:
+		return compareIntFloat(a.i, b.f)
+	case b.isInt:
+		return -compareIntFloat(b.i, a.f)
+	}
+
+	switch {
+	case a.f < b.f:
+		return -1
+	case a.f > b.f:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareInts(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Compare integer to float without converting integer to float, which would round large values. f must not be NaN.
+func compareIntFloat(i int64, f float64) int {
+	switch {
+	case f >= math.MaxInt64: // 2^63, float64 can't hold MaxInt64 exactly
+		return -1
+	case f < math.MinInt64:
+		return 1
+	}
+
+	fi := math.Floor(f)
+	if c := compareInts(i, int64(fi)); c != 0 {
+		return c
+	}
+
+	if f > fi {
+		// f has fraction, so it's larger than its integer part
+		return -1
+	}
+
+	return 0
+}
+
+func toString(v interface{}) (string, bool) {
+	switch s := v.(type) {
+	case string:
+		return s, true
+	case []byte:
+		return strings.TrimSpace(string(s)), true
+	case fmt.Stringer:
+		return s.String(), true
+	default:
+		return ``, false
+	}
+}
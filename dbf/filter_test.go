@@ -0,0 +1,147 @@
+package dbf
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFilterMatch(t *testing.T) {
+	row := map[string]Record{
+		`POP`:  {Value: int64(1500)},
+		`AREA`: {Value: `12.5`},
+		`NAME`: {Value: `Helsinki`},
+		`CODE`: {Value: nil},
+		`BIG`:  {Value: int64(9007199254740993)}, // 2^53 + 1, not exact as float64
+		`NAN`:  {Value: math.NaN()},
+	}
+
+	tests := []struct {
+		expression string
+		expected   bool
+	}{
+		{`POP > 1000 AND NAME LIKE 'Hel%'`, true},
+		{`POP > 1000 AND NAME LIKE 'hel%'`, false},
+		{`POP <= 1000 OR NAME = 'Helsinki'`, true},
+		{`NOT (POP >= 1500)`, false},
+		{`AREA < 13 AND AREA > 12`, true},
+		{`NAME LIKE 'H_lsink_'`, true},
+		{`NAME NOT LIKE '%x%'`, true},
+		{`CODE IS NULL AND NAME IS NOT NULL`, true},
+		{`CODE = 1`, false},
+		{`NAME IN ('Espoo', 'Helsinki')`, true},
+		{`POP NOT IN (1, 2, 1500)`, false},
+		{`"NAME" <> 'Espoo'`, true},
+		{`POP = -1.5e3 OR POP = 1.5e3`, true},
+		// NULL is unknown, also when negated
+		{`NOT (CODE = 1)`, false},
+		{`CODE NOT LIKE 'x%'`, false},
+		{`CODE NOT IN (1, 2)`, false},
+		{`CODE = 1 OR NAME = 'Helsinki'`, true},
+		{`NOT (CODE = 1 AND POP = 1)`, true},
+		{`NOT (CODE = 1 OR POP = 1)`, false},
+		{`POP IN (1, CODE)`, false},
+		{`POP NOT IN (1, CODE)`, false},
+		// Integers keep their precision
+		{`BIG = 9007199254740992`, false},
+		{`BIG > 9007199254740992`, true},
+		{`BIG = 9007199254740993`, true},
+		{`BIG < 9007199254740993.5`, true},
+		{`POP = '1500'`, true},
+		// NaN is unknown like NULL
+		{`NAN = 5`, false},
+		{`NAN = 5.5`, false},
+		{`NOT (NAN = 5)`, false},
+		{`NAN != 5 OR NAN < 5 OR NAN >= 5`, false},
+		{`NAN = 5 OR POP = 1500`, true},
+	}
+
+	for _, tt := range tests {
+		f, err := ParseFilter(tt.expression)
+		if err != nil {
+			t.Fatalf(`%v: %v`, tt.expression, err)
+		}
+
+		actual, err := f.Match(row)
+		if err != nil {
+			t.Fatalf(`%v: %v`, tt.expression, err)
+		}
+
+		if actual != tt.expected {
+			t.Fatalf(`%v: result was %v, should be %v`, tt.expression, actual, tt.expected)
+		}
+	}
+}
+
+func TestFilterTypeMismatch(t *testing.T) {
+	f, err := ParseFilter(`NAME > 5`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = f.Match(map[string]Record{`NAME`: {Value: `abc`}})
+	if _, ok := err.(*FilterTypeMismatch); !ok {
+		t.Fatalf(`error was %v, should be type mismatch`, err)
+	}
+}
+
+func TestFilterFieldNameIsCaseSensitive(t *testing.T) {
+	f, err := ParseFilter(`pop > 1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = f.Match(map[string]Record{`POP`: {Value: int64(2)}})
+	if _, ok := err.(*FilterFieldNotFound); !ok {
+		t.Fatalf(`error was %v, should be field not found`, err)
+	}
+}
+
+func TestFilterSyntaxError(t *testing.T) {
+	for _, expression := range []string{
+		``,
+		`POP >`,
+		`POP > 1 AND`,
+		`(POP > 1`,
+		`POP ! 1`,
+		`NAME LIKE 5`,
+		`NAME IS 5`,
+		`NAME = 'abc`,
+		`POP > 1 POP`,
+		`POP`,
+	} {
+		_, err := ParseFilter(expression)
+		if _, ok := err.(*FilterSyntaxError); !ok {
+			t.Fatalf(`%q: error was %v, should be syntax error`, expression, err)
+		}
+	}
+}
+
+func TestFilterFields(t *testing.T) {
+	f, err := ParseFilter(`A = 1 OR (B = 2 AND A = 3)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fields := f.Fields()
+	if len(fields) != 2 || fields[0] != `A` || fields[1] != `B` {
+		t.Fatalf(`fields were %v, should be [A B]`, fields)
+	}
+
+	db := DBaseFile{
+		FieldDescriptors:         []FieldDescriptor{{Name: `A`}, {Name: `B`}},
+		parseFieldNames:          []string{`B`},
+		parseFieldNamesOperation: SkipThese,
+		initialized:              true,
+	}
+
+	err = db.SetFilter(f)
+	if e, ok := err.(*FilterFieldNotFound); !ok || e.Name != `B` {
+		t.Fatalf(`error was %v, should be field B not found`, err)
+	}
+
+	db.parseFieldNamesOperation = KeepAll
+	err = db.SetFilter(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
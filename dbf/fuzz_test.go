@@ -0,0 +1,54 @@
+package dbf
+
+import (
+	"github.com/raspi/GeoESRIShapeFile/common"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func FuzzReadRecord(f *testing.F) {
+	files, err := filepath.Glob(`../_test_files/*.dbf`)
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	for _, fpath := range files {
+		b, err := ioutil.ReadFile(fpath)
+		if err != nil {
+			f.Fatal(err)
+		}
+
+		f.Add(b)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		r, err := common.NewReadSeekCloser(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		db := DBaseFile{
+			r:                            r,
+			useDefaultConverterIfMissing: true,
+			defaultConverter:             DefaultConverterToString,
+		}
+
+		err = db.Initialize()
+		if err != nil {
+			return
+		}
+
+		for i := 0; i < db.Header.RecordCount; i++ {
+			_, err = db.ReadRecord()
+			if err == io.EOF {
+				return
+			}
+
+			if err != nil && err != ErrorDeletedRecord {
+				return
+			}
+		}
+	})
+}
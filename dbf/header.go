@@ -3,6 +3,7 @@ package dbf
 import (
 	"encoding/binary"
 	"fmt"
+	"io"
 	"time"
 )
 
@@ -41,6 +42,8 @@ type Header struct {
 
 	RecordCount int // How many records?
 	RecordSize  int // How many bytes is each record
+
+	LanguageDriver LanguageDriver // Code page mark, see codepage.go
 }
 
 // Read main header
@@ -75,6 +78,11 @@ func (db *DBaseFile) readHeader() (err error) {
 		return fmt.Errorf(`rawField size should be 32, is %v`, rawFieldBinSize)
 	}
 
+	// Main header and terminator character at least
+	if int64(rawhdr.LengthHeaderBytes) < rawHeaderBinSize+1 {
+		return fmt.Errorf(`header length is %v, should be at least %v`, rawhdr.LengthHeaderBytes, rawHeaderBinSize+1)
+	}
+
 	rawFieldCount := int(rawhdr.LengthHeaderBytes)/rawFieldBinSize - 1
 
 	db.offsets.mainHeaderEnd = offset
@@ -88,6 +96,8 @@ func (db *DBaseFile) readHeader() (err error) {
 		RecordCount: int(rawhdr.RecordCount),
 		RecordSize:  int(rawhdr.LengthRecordBytes),
 		FieldCount:  rawFieldCount,
+
+		LanguageDriver: LanguageDriver(rawhdr.LanguageDriver),
 	}
 
 	if !isSupportedVersion(db.Header.Version) {
@@ -105,7 +115,7 @@ func (db *DBaseFile) readTerminator() (err error) {
 	}
 
 	terminator := make([]byte, 1)
-	_, err = db.r.Read(terminator)
+	_, err = io.ReadFull(db.r, terminator)
 	if err != nil {
 		return err
 	}
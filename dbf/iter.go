@@ -0,0 +1,67 @@
+//go:build go1.23
+
+package dbf
+
+import (
+	"github.com/raspi/GeoESRIShapeFile/common"
+	"io"
+	"iter"
+)
+
+/*
+Iterator over rows read with ReadRecord from current position, with 0-based row number:
+
+	for n, row := range db.Rows() {
+		...
+	}
+	if err := db.Err(); err != nil {
+		...
+	}
+
+Deleted rows and rows not matching filter are skipped. Iteration ends after last row given in
+header or at end of file marker, or at first error, see Err.
+*/
+func (db *DBaseFile) Rows() iter.Seq2[int, map[string]Record] {
+	return func(yield func(int, map[string]Record) bool) {
+		db.iterErr = nil
+
+		if !db.initialized {
+			db.iterErr = common.ErrorNotInitialized
+			return
+		}
+
+		offset, err := db.Offset()
+		if err != nil {
+			db.iterErr = err
+			return
+		}
+
+		n := 0
+		if offset > db.offsets.terminatorEnd && db.Header.RecordSize > 0 {
+			n = int((offset - db.offsets.terminatorEnd) / int64(db.Header.RecordSize))
+		}
+
+		for ; n < db.Header.RecordCount; n++ {
+			row, err := db.ReadRecord()
+			switch err {
+			case nil:
+			case ErrorDeletedRecord, ErrorFilteredRecord:
+				continue
+			case io.EOF:
+				return
+			default:
+				db.iterErr = err
+				return
+			}
+
+			if !yield(n, row) {
+				return
+			}
+		}
+	}
+}
+
+// Error which stopped Rows iterator, nil if it reached end of file or loop was exited with break
+func (db *DBaseFile) Err() error {
+	return db.iterErr
+}
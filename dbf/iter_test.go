@@ -0,0 +1,37 @@
+//go:build go1.23
+
+package dbf
+
+import (
+	"testing"
+)
+
+func TestRows(t *testing.T) {
+	db, err := Open(writeTestFile(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	err = db.Initialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var codes []interface{}
+	for n, row := range db.Rows() {
+		if n != len(codes) {
+			t.Errorf(`row number %d, should be %d`, n, len(codes))
+		}
+
+		codes = append(codes, row[`CODE`].Value)
+	}
+
+	if err := db.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(codes) != 2 || codes[0] != `001` || codes[1] != `002` {
+		t.Fatalf(`read %v`, codes)
+	}
+}
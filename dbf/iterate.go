@@ -0,0 +1,65 @@
+package dbf
+
+import (
+	"context"
+	"github.com/raspi/GeoESRIShapeFile/common"
+	"io"
+)
+
+/*
+Call fn for every row read with ReadRecord, starting from current position, until end of file.
+n is 0-based row number. Deleted rows and rows not matching filter are skipped but counted in
+progress. Stops when ctx is cancelled, returning ctx.Err(), or when reading fails or fn returns
+an error. progress may be nil.
+*/
+func (db *DBaseFile) ForEach(ctx context.Context, fn func(n int, row map[string]Record) error, progress common.ProgressFunc) error {
+	if !db.initialized {
+		return common.ErrorNotInitialized
+	}
+
+	offset, err := db.Offset()
+	if err != nil {
+		return err
+	}
+
+	n := 0
+	if offset > db.offsets.terminatorEnd && db.Header.RecordSize > 0 {
+		n = int((offset - db.offsets.terminatorEnd) / int64(db.Header.RecordSize))
+	}
+
+	p := common.Progress{
+		TotalRecords: int64(db.Header.RecordCount),
+		TotalBytes:   db.offsets.terminatorEnd + int64(db.Header.RecordCount)*int64(db.Header.RecordSize),
+	}
+
+	for ; ; n++ {
+		err = ctx.Err()
+		if err != nil {
+			return err
+		}
+
+		if n >= db.Header.RecordCount {
+			return nil
+		}
+
+		row, err := db.ReadRecord()
+		switch err {
+		case nil:
+			err = fn(n, row)
+			if err != nil {
+				return err
+			}
+		case ErrorDeletedRecord, ErrorFilteredRecord:
+		case io.EOF:
+			return nil
+		default:
+			return err
+		}
+
+		if progress != nil {
+			p.Records = int64(n + 1)
+			p.Bytes = db.offsets.terminatorEnd + int64(n+1)*int64(db.Header.RecordSize)
+			progress(p)
+		}
+	}
+}
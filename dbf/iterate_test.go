@@ -0,0 +1,41 @@
+package dbf
+
+import (
+	"context"
+	"github.com/raspi/GeoESRIShapeFile/common"
+	"testing"
+)
+
+func TestForEach(t *testing.T) {
+	db, err := Open(writeTestFile(t), WithFields(`CODE`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	err = db.Initialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var codes []interface{}
+	var last common.Progress
+
+	err = db.ForEach(context.Background(), func(n int, row map[string]Record) error {
+		codes = append(codes, row[`CODE`].Value)
+		return nil
+	}, func(p common.Progress) {
+		last = p
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(codes) != 2 || codes[1] != `002` {
+		t.Fatalf(`read %v`, codes)
+	}
+
+	if last.Records != 2 || last.TotalRecords != 2 || last.Bytes != last.TotalBytes || last.Fraction() != 1 {
+		t.Fatalf(`last progress %+v`, last)
+	}
+}
@@ -0,0 +1,96 @@
+package dbf
+
+import (
+	"github.com/raspi/GeoESRIShapeFile/common"
+	"golang.org/x/text/encoding"
+	"io"
+)
+
+// Option changes how dBase file is read, see Open
+type Option func(db *DBaseFile)
+
+// Read only listed fields
+func WithFields(names ...string) Option {
+	return func(db *DBaseFile) {
+		db.parseFieldNames = names
+		db.parseFieldNamesOperation = KeepOnlyListed
+	}
+}
+
+// Read all fields except listed
+func WithoutFields(names ...string) Option {
+	return func(db *DBaseFile) {
+		db.parseFieldNames = names
+		db.parseFieldNamesOperation = SkipThese
+	}
+}
+
+// Convert field with given function instead of default converter
+func WithConverter(field string, fn ConverterFunction) Option {
+	return func(db *DBaseFile) {
+		if db.converterFunctions == nil {
+			db.converterFunctions = make(map[string]ConverterFunction)
+		}
+
+		db.converterFunctions[field] = fn
+	}
+}
+
+// Converter for fields without their own converter. Default is DefaultConverterToString.
+func WithDefaultConverter(fn ConverterFunction) Option {
+	return func(db *DBaseFile) {
+		db.defaultConverter = fn
+	}
+}
+
+// Decode Character and Memo fields from given character encoding (such as charmap.Windows1252)
+// to UTF-8 before converting them. Default is to pass bytes as-is.
+func WithEncoding(enc encoding.Encoding) Option {
+	return func(db *DBaseFile) {
+		db.encoding = enc
+		db.decoder = nil
+	}
+}
+
+// Log with given logger, for example *slog.Logger. Default is no logging.
+func WithLogger(l common.Logger) Option {
+	return func(db *DBaseFile) {
+		db.logger = l
+	}
+}
+
+// Open dBase file. Initialize must be called before reading records.
+func Open(fname string, opts ...Option) (db DBaseFile, err error) {
+	f, err := common.OpenFile(fname)
+	if err != nil {
+		return db, err
+	}
+
+	return OpenFrom(f, opts...), nil
+}
+
+// Read r in one pass, for example stdin or HTTP body. ReadRecordAt doesn't work.
+func OpenStream(r io.Reader, opts ...Option) DBaseFile {
+	return OpenFrom(common.NewStream(r), opts...)
+}
+
+// Read from r of given size, for example from object storage with ranged reads. ReadRecordAt is one ranged read.
+func OpenReaderAt(r io.ReaderAt, size int64, opts ...Option) DBaseFile {
+	return OpenFrom(common.NewReaderAt(r, size), opts...)
+}
+
+// Read from already opened file, for example from afero.Fs or fs.FS. Close closes r.
+func OpenFrom(r common.ReadSeekCloser, opts ...Option) (db DBaseFile) {
+	db = DBaseFile{
+		r:                            r,
+		useDefaultConverterIfMissing: true,
+		defaultConverter:             DefaultConverterToString,
+		parseFieldNamesOperation:     KeepAll,
+	}
+
+	for _, opt := range opts {
+		opt(&db)
+	}
+
+	return db
+}
@@ -0,0 +1,104 @@
+package dbf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"golang.org/x/text/encoding/charmap"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+// Write dBase III file with Character fields NAME (6) and CODE (3) and ISO 8859-1 encoded rows
+func writeTestFile(t testing.TB) string {
+	return writeTestRows(t, [][]byte{
+		[]byte(" K\xe4rki 001"),
+		[]byte(" \xc5land 002"),
+	})
+}
+
+// Write dBase III file with fields of writeTestFile and given rows, including deleted flag
+func writeTestRows(t testing.TB, rows [][]byte) string {
+	var buf bytes.Buffer
+
+	fields := []rawFieldDescriptor{
+		{Type: Character, Length: 6},
+		{Type: Character, Length: 3},
+	}
+	copy(fields[0].Name[:], `NAME`)
+	copy(fields[1].Name[:], `CODE`)
+
+	hdr := rawHeader{
+		Version:           VerdBASEIII,
+		UpdateYear:        120,
+		UpdateMonth:       1,
+		UpdateDay:         1,
+		RecordCount:       uint32(len(rows)),
+		LengthHeaderBytes: uint16(32 + 32*len(fields) + 1),
+		LengthRecordBytes: 1 + 6 + 3,
+	}
+
+	_ = binary.Write(&buf, binary.LittleEndian, hdr)
+	_ = binary.Write(&buf, binary.LittleEndian, fields)
+	buf.WriteByte(TerminatorCharacter)
+
+	for _, row := range rows {
+		buf.Write(row)
+	}
+
+	buf.WriteByte(0x1a)
+
+	fpath := filepath.Join(t.TempDir(), `test.dbf`)
+	err := ioutil.WriteFile(fpath, buf.Bytes(), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return fpath
+}
+
+func readAll(t *testing.T, fpath string, opts ...Option) (rows []map[string]Record) {
+	db, err := Open(fpath, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	err = db.Initialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for idx := 0; idx < db.Header.RecordCount; idx++ {
+		row, err := db.ReadRecord()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+func TestOpenWithEncoding(t *testing.T) {
+	rows := readAll(t, writeTestFile(t), WithEncoding(charmap.ISO8859_1))
+
+	if rows[0][`NAME`].Value != `Kärki` || rows[1][`NAME`].Value != `Åland` {
+		t.Fatalf(`decoded %v`, rows)
+	}
+}
+
+func TestOpenWithFields(t *testing.T) {
+	fpath := writeTestFile(t)
+
+	rows := readAll(t, fpath, WithFields(`CODE`))
+	if len(rows[1]) != 1 || rows[1][`CODE`].Value != `002` {
+		t.Fatalf(`WithFields read %v`, rows)
+	}
+
+	rows = readAll(t, fpath, WithoutFields(`NAME`), WithConverter(`CODE`, DefaultConverterToInt))
+	if len(rows[0]) != 1 || rows[0][`CODE`].Value != int64(1) {
+		t.Fatalf(`WithoutFields read %v`, rows)
+	}
+}
@@ -1,10 +1,11 @@
 package dbf
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
 	"github.com/raspi/GeoESRIShapeFile/common"
+	"golang.org/x/text/encoding"
+	"golang.org/x/xerrors"
 	"io"
 )
 
@@ -25,16 +26,69 @@ func (r Record) String() string {
 	return fmt.Sprintf(`'%#v'`, r.Value)
 }
 
+// Read n:th (0-based) record
+func (db *DBaseFile) ReadRecordAt(n int) (m map[string]Record, err error) {
+	if !db.initialized {
+		return nil, common.ErrorNotInitialized
+	}
+
+	_, err = db.r.Seek(db.offsets.terminatorEnd+int64(n)*int64(db.Header.RecordSize), io.SeekStart)
+	if err != nil {
+		return nil, err
+	}
+
+	return db.ReadRecord()
+}
+
 func (db *DBaseFile) ReadRecord() (m map[string]Record, err error) {
 	if !db.initialized {
 		return nil, common.ErrorNotInitialized
 	}
 
+	// Converters may keep the bytes they are given, so every row gets its own buffer
+	rawalldata, err := db.readRaw(make([]byte, db.Header.RecordSize))
+	if err != nil {
+		return nil, err
+	}
+
 	m = make(map[string]Record, db.Header.FieldCount)
 
-	rawalldata := make([]byte, db.Header.RecordSize)
-	rBytesAll, err := db.r.Read(rawalldata)
+	err = db.decodeRecord(rawalldata, db.decoder, m)
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+/*
+Same as ReadRecord, but decoded fields are stored to row, which can be reused for every row. Other
+keys in row are left as-is. Row bytes are read to a buffer which is reused, so converters must not
+keep the slice they are given; default converters don't. row has partial or filtered values if an
+error is returned.
+*/
+func (db *DBaseFile) ReadRecordInto(row map[string]Record) error {
+	if !db.initialized {
+		return common.ErrorNotInitialized
+	}
+
+	if cap(db.buf) < db.Header.RecordSize {
+		db.buf = make([]byte, db.Header.RecordSize)
+	}
+
+	rawalldata, err := db.readRaw(db.buf[:db.Header.RecordSize])
 	if err != nil {
+		return err
+	}
+
+	return db.decodeRecord(rawalldata, db.decoder, row)
+}
+
+// Read raw record to rawalldata, which is record size long
+func (db *DBaseFile) readRaw(rawalldata []byte) ([]byte, error) {
+	// Streams and pipes may return less than asked from single Read
+	rBytesAll, err := io.ReadFull(db.r, rawalldata)
+	if err != nil && err != io.ErrUnexpectedEOF {
 		return nil, err
 	}
 
@@ -47,68 +101,60 @@ func (db *DBaseFile) ReadRecord() (m map[string]Record, err error) {
 		return nil, fmt.Errorf("full record size mismatch header is %v, had %v:\n%#v", db.Header.RecordSize, rBytesAll, rawalldata[:rBytesAll])
 	}
 
-	r := bytes.NewReader(rawalldata)
+	if db.encoding != nil && db.decoder == nil {
+		db.decoder = db.encoding.NewDecoder()
+	}
 
-	first, err := r.ReadByte()
-	if err != nil {
-		return nil, err
+	return rawalldata, nil
+}
+
+// Decode raw record to m. Fields are sliced from rawalldata without copying. dec is nil if
+// encoding is not set, decoders can't be shared between goroutines.
+func (db *DBaseFile) decodeRecord(rawalldata []byte, dec *encoding.Decoder, m map[string]Record) (err error) {
+	if len(rawalldata) == 0 {
+		return io.EOF
 	}
 
-	switch RecordFirstCharacter(first) {
+	switch first := rawalldata[0]; RecordFirstCharacter(first) {
 	default:
-		return nil, fmt.Errorf(`weird first byte: %[1]d %[1]c %[1]v`, first)
+		return fmt.Errorf(`weird first byte: %[1]d %[1]c %[1]v`, first)
 	case DeletedRecord: // deleted record
-		return nil, ErrorDeletedRecord
+		return ErrorDeletedRecord
 	case OkRecord: // ok
 	}
 
+	pos := 1
+
 	for _, f := range db.FieldDescriptors {
 
 		if !isSupportedDataType(f.Type) {
-			return nil, NewErrorNotSupportedDataType(f.Type)
+			return NewErrorNotSupportedDataType(f.Type)
 		}
 
-		skip := false // default keep all
-
-		if db.parseFieldNamesOperation != KeepAll {
-			if db.parseFieldNamesOperation == KeepOnlyListed {
-				// skip all by default
-				skip = true
-			}
-
-			for _, fn := range db.parseFieldNames {
-				if fn == f.Name {
-					switch db.parseFieldNamesOperation {
-					case KeepOnlyListed:
-						skip = false
-					case SkipThese:
-						skip = true
-					}
-
-					break
-				}
-			}
+		if f.Length > len(rawalldata)-pos {
+			return fmt.Errorf(`record size mismatch %v != %v`, len(rawalldata)-pos, f.Length)
 		}
 
-		if skip {
-			continue
-		}
+		rawdata := rawalldata[pos : pos+f.Length]
+		pos += f.Length
 
-		rawdata := make([]byte, f.Length)
-		rBytes, err := r.Read(rawdata)
-		if err != nil {
-			return nil, err
+		// Skipped field must still be read so that next field starts at correct position
+		if db.isSkippedField(f.Name) {
+			continue
 		}
 
-		if rBytes != f.Length {
-			return nil, fmt.Errorf(`record size mismatch %v != %v`, rBytes, f.Length)
+		if dec != nil && (f.Type == Character || f.Type == MemoData) {
+			rawdata, err = dec.Bytes(rawdata)
+			if err != nil {
+				return xerrors.Errorf(`field %v: %w`, f.Name, err)
+			}
 		}
 
 		// Find converter
 		converter, ok := db.converterFunctions[f.Name]
 
 		if !ok && !db.useDefaultConverterIfMissing {
-			return nil, fmt.Errorf(`no such converter: %v %v`, f.Type, f.Name)
+			return fmt.Errorf(`no such converter: %v %v`, f.Type, f.Name)
 		}
 
 		if !ok {
@@ -117,12 +163,38 @@ func (db *DBaseFile) ReadRecord() (m map[string]Record, err error) {
 
 		rec, err := converter(rawdata)
 		if err != nil {
-			return nil, err
+			return err
 		}
 
 		m[f.Name] = rec
 
 	}
 
-	return m, nil
+	if db.filter != nil {
+		ok, err := db.filter.Match(m)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			return ErrorFilteredRecord
+		}
+	}
+
+	return nil
+}
+
+// Is field skipped by parseFieldNames and parseFieldNamesOperation
+func (db *DBaseFile) isSkippedField(name string) bool {
+	if db.parseFieldNamesOperation == KeepAll {
+		return false
+	}
+
+	for _, fn := range db.parseFieldNames {
+		if fn == name {
+			return db.parseFieldNamesOperation == SkipThese
+		}
+	}
+
+	return db.parseFieldNamesOperation == KeepOnlyListed
 }
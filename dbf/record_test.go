@@ -0,0 +1,168 @@
+package dbf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/raspi/GeoESRIShapeFile/common"
+	"golang.org/x/text/encoding/charmap"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func openInitialized(tb testing.TB, fpath string, opts ...Option) DBaseFile {
+	db, err := Open(fpath, opts...)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	err = db.Initialize()
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return db
+}
+
+func TestReadRecordInto(t *testing.T) {
+	fpath := writeTestFile(t)
+	want := readAll(t, fpath, WithEncoding(charmap.ISO8859_1))
+
+	db := openInitialized(t, fpath, WithEncoding(charmap.ISO8859_1))
+	defer db.Close()
+
+	row := map[string]Record{}
+
+	for n := range want {
+		err := db.ReadRecordInto(row)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(row, want[n]) {
+			t.Errorf(`row #%d is %v, should be %v`, n, row, want[n])
+		}
+	}
+
+	err := db.ReadRecordInto(row)
+	if err != io.EOF {
+		t.Fatalf(`expected io.EOF, got %v`, err)
+	}
+}
+
+func TestReadRecordIntoAllocs(t *testing.T) {
+	// Converter which doesn't allocate, so only reading is measured
+	length := func(data []byte) (Record, error) {
+		return Record{Value: len(data)}, nil
+	}
+
+	db := openInitialized(t, writeTestFile(t), WithDefaultConverter(length))
+	defer db.Close()
+
+	row := map[string]Record{}
+
+	allocs := testing.AllocsPerRun(50, func() {
+		_, err := db.r.Seek(db.offsets.terminatorEnd, io.SeekStart)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = db.ReadRecordInto(row)
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	if allocs != 0 {
+		t.Errorf(`ReadRecordInto allocated %v times per row, should be 0`, allocs)
+	}
+}
+
+func benchmarkRows(b *testing.B, read func(db *DBaseFile) error) {
+	db := openInitialized(b, writeTestFile(b))
+	defer db.Close()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		err := read(&db)
+		if err == io.EOF {
+			_, err = db.r.Seek(db.offsets.terminatorEnd, io.SeekStart)
+		}
+
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkReadRecord(b *testing.B) {
+	benchmarkRows(b, func(db *DBaseFile) error {
+		_, err := db.ReadRecord()
+		return err
+	})
+}
+
+func BenchmarkReadRecordInto(b *testing.B) {
+	row := map[string]Record{}
+
+	benchmarkRows(b, func(db *DBaseFile) error {
+		return db.ReadRecordInto(row)
+	})
+}
+
+// Field after skipped one must be read from its own position in the row
+func TestReadRecordAfterSkippedField(t *testing.T) {
+	var buf bytes.Buffer
+
+	fields := []rawFieldDescriptor{
+		{Type: Character, Length: 6},
+		{Type: Character, Length: 3},
+	}
+	copy(fields[0].Name[:], `NAME`)
+	copy(fields[1].Name[:], `CODE`)
+
+	hdr := rawHeader{
+		Version:           VerdBASEIII,
+		UpdateYear:        120,
+		UpdateMonth:       1,
+		UpdateDay:         1,
+		RecordCount:       1,
+		LengthHeaderBytes: uint16(32 + 32*len(fields) + 1),
+		LengthRecordBytes: 1 + 6 + 3,
+	}
+
+	_ = binary.Write(&buf, binary.LittleEndian, hdr)
+	_ = binary.Write(&buf, binary.LittleEndian, fields)
+	buf.WriteByte(TerminatorCharacter)
+	buf.WriteString(` Turku 001`)
+	buf.WriteByte(0x1a)
+
+	r, err := common.NewReadSeekCloser(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db := DBaseFile{
+		r:                            r,
+		parseFieldNames:              []string{`NAME`},
+		parseFieldNamesOperation:     SkipThese,
+		useDefaultConverterIfMissing: true,
+		defaultConverter:             DefaultConverterToString,
+	}
+
+	err = db.Initialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	row, err := db.ReadRecord()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(row) != 1 || row[`CODE`].Value != `001` {
+		t.Fatalf(`read %v, should be only CODE 001`, row)
+	}
+}
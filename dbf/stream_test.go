@@ -0,0 +1,47 @@
+package dbf
+
+import (
+	"io"
+	"os"
+	"reflect"
+	"testing"
+	"testing/iotest"
+)
+
+func TestOpenStream(t *testing.T) {
+	fpath := writeTestFile(t)
+	want := readAll(t, fpath)
+
+	f, err := os.Open(fpath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	// Pipes and network connections return partial reads
+	db := OpenStream(iotest.OneByteReader(f))
+
+	err = db.Initialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []map[string]Record
+	for idx := 0; idx < db.Header.RecordCount; idx++ {
+		row, err := db.ReadRecord()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got = append(got, row)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf(`streamed %v, should be %v`, got, want)
+	}
+
+	_, err = db.ReadRecord()
+	if err != io.EOF {
+		t.Fatalf(`expected io.EOF after last row, got %v`, err)
+	}
+}
@@ -0,0 +1,128 @@
+package geoesrishapefile
+
+import (
+	"github.com/raspi/GeoESRIShapeFile/common"
+	"github.com/spf13/afero"
+	"io/fs"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+)
+
+// Filesystem which dataset files are opened from
+type source interface {
+	readDir(dir string) (names []string, err error) // File names in dir, directories excluded
+	open(name string) (common.ReadSeekCloser, error)
+	split(fpath string) (dir, fname string)
+	join(dir, fname string) string
+}
+
+/*
+Open dataset from afero filesystem, for example afero.NewMemMapFs() in tests or layered filesystems.
+name is path of .shp or base name without extension.
+*/
+func OpenAfero(fsys afero.Fs, name string, opts ...Option) (ShapeFiles, error) {
+	return open(aferoSource{fsys: fsys}, name, opts)
+}
+
+/*
+Open dataset from io/fs filesystem, for example embed.FS or os.DirFS. name is slash separated
+path of .shp or base name without extension, see fs.ValidPath.
+*/
+func OpenFS(fsys fs.FS, name string, opts ...Option) (ShapeFiles, error) {
+	if !fs.ValidPath(name) {
+		return ShapeFiles{}, &fs.PathError{Op: `open`, Path: name, Err: fs.ErrInvalid}
+	}
+
+	return open(ioFSSource{fsys: fsys}, name, opts)
+}
+
+// Files on disk
+type osSource struct{}
+
+func (osSource) readDir(dir string) ([]string, error) {
+	return fileNames(ioutil.ReadDir(dir))
+}
+
+func (osSource) open(name string) (common.ReadSeekCloser, error) {
+	return common.OpenFile(name)
+}
+
+func (osSource) split(fpath string) (string, string) {
+	return filepath.Dir(fpath), filepath.Base(fpath)
+}
+
+func (osSource) join(dir, fname string) string {
+	return filepath.Join(dir, fname)
+}
+
+type aferoSource struct {
+	fsys afero.Fs
+}
+
+func (s aferoSource) readDir(dir string) ([]string, error) {
+	return fileNames(afero.ReadDir(s.fsys, dir))
+}
+
+func (s aferoSource) open(name string) (common.ReadSeekCloser, error) {
+	return common.OpenAferoFile(s.fsys, name)
+}
+
+func (aferoSource) split(fpath string) (string, string) {
+	return filepath.Dir(fpath), filepath.Base(fpath)
+}
+
+func (aferoSource) join(dir, fname string) string {
+	return filepath.Join(dir, fname)
+}
+
+type ioFSSource struct {
+	fsys fs.FS
+}
+
+func (s ioFSSource) readDir(dir string) ([]string, error) {
+	entries, err := fs.ReadDir(s.fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+
+		names = append(names, e.Name())
+	}
+
+	return names, nil
+}
+
+func (s ioFSSource) open(name string) (common.ReadSeekCloser, error) {
+	return common.OpenFSFile(s.fsys, name)
+}
+
+func (ioFSSource) split(fpath string) (string, string) {
+	return path.Dir(fpath), path.Base(fpath)
+}
+
+func (ioFSSource) join(dir, fname string) string {
+	return path.Join(dir, fname)
+}
+
+// Names of files, directories excluded
+func fileNames(list []fs.FileInfo, err error) (names []string, _ error) {
+	if err != nil {
+		return nil, err
+	}
+
+	for _, fi := range list {
+		if fi.IsDir() {
+			continue
+		}
+
+		names = append(names, fi.Name())
+	}
+
+	return names, nil
+}
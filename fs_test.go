@@ -0,0 +1,175 @@
+package geoesrishapefile
+
+import (
+	"errors"
+	"github.com/spf13/afero"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+// Count records and rows of opened dataset
+func countRecords(t *testing.T, sf ShapeFiles) (records, rows int) {
+	defer sf.Close()
+
+	for {
+		_, _, err := sf.Fshp.ReadRecord()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		records++
+	}
+
+	for {
+		_, err := sf.Fdbf.ReadRecord()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rows++
+	}
+
+	return records, rows
+}
+
+func TestOpenFS(t *testing.T) {
+	for _, name := range []string{`polyline.shp`, `polyline`} {
+		sf, err := OpenFS(os.DirFS(`_test_files`), name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if sf.Files[`shx`] != `polyline.shx` {
+			t.Errorf(`.shx path is %q`, sf.Files[`shx`])
+		}
+
+		records, rows := countRecords(t, sf)
+		if records != 2 || rows != 2 {
+			t.Errorf(`%v: read %d records and %d rows`, name, records, rows)
+		}
+	}
+}
+
+func TestOpenFSMap(t *testing.T) {
+	fsys := fstest.MapFS{}
+
+	for _, ext := range []string{`.shp`, `.shx`, `.dbf`} {
+		data, err := ioutil.ReadFile(filepath.Join(`_test_files`, `point`+ext))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		fsys[`data/point`+ext] = &fstest.MapFile{Data: data}
+	}
+
+	sf, err := OpenFS(fsys, `data/point`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records, rows := countRecords(t, sf)
+	if records != 3 || rows != 3 {
+		t.Errorf(`read %d records and %d rows`, records, rows)
+	}
+
+	_, err = OpenFS(fsys, `data/missing`)
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf(`expected fs.ErrNotExist, got %v`, err)
+	}
+}
+
+func TestOpenAfero(t *testing.T) {
+	fsys := afero.NewMemMapFs()
+
+	for _, ext := range []string{`.shp`, `.shx`, `.dbf`} {
+		data, err := ioutil.ReadFile(filepath.Join(`_test_files`, `point`+ext))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = afero.WriteFile(fsys, filepath.Join(`data`, `point`+ext), data, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sf, err := OpenAfero(fsys, filepath.Join(`data`, `point.shp`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records, rows := countRecords(t, sf)
+	if records != 3 || rows != 3 {
+		t.Errorf(`read %d records and %d rows`, records, rows)
+	}
+}
+
+// Broken spatial bin index is optional and must not prevent opening
+func TestOpenBrokenSbn(t *testing.T) {
+	fsys := fstest.MapFS{
+		`point.sbn`: &fstest.MapFile{Data: []byte(`not a spatial bin index`)},
+		`point.sbx`: &fstest.MapFile{Data: []byte(`not a spatial bin index`)},
+	}
+
+	for _, ext := range []string{`.shp`, `.shx`, `.dbf`} {
+		data, err := ioutil.ReadFile(filepath.Join(`_test_files`, `point`+ext))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		fsys[`point`+ext] = &fstest.MapFile{Data: data}
+	}
+
+	sf, err := OpenFS(fsys, `point`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if sf.Fsbn != nil || sf.Fsbx != nil {
+		t.Errorf(`broken .sbn and .sbx should be left out`)
+	}
+
+	records, rows := countRecords(t, sf)
+	if records != 3 || rows != 3 {
+		t.Errorf(`read %d records and %d rows`, records, rows)
+	}
+}
+
+// Dots in base name are not extension
+func TestOpenFSDottedName(t *testing.T) {
+	fsys := fstest.MapFS{}
+
+	for _, ext := range []string{`.shp`, `.shx`, `.dbf`} {
+		data, err := ioutil.ReadFile(filepath.Join(`_test_files`, `point`+ext))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		fsys[`roads.v2`+ext] = &fstest.MapFile{Data: data}
+	}
+
+	for _, name := range []string{`roads.v2`, `roads.v2.shp`, `roads.v2.SHP`} {
+		sf, err := OpenFS(fsys, name)
+		if err != nil {
+			t.Fatalf(`%v: %v`, name, err)
+		}
+
+		records, rows := countRecords(t, sf)
+		if records != 3 || rows != 3 {
+			t.Errorf(`%v: read %d records and %d rows`, name, records, rows)
+		}
+	}
+}
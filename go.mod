@@ -1,8 +1,9 @@
 module github.com/raspi/GeoESRIShapeFile
 
-go 1.12
+go 1.18
 
 require (
 	github.com/spf13/afero v1.2.2
+	golang.org/x/text v0.3.0
 	golang.org/x/xerrors v0.0.0-20190717185122-a985d3407aa7
 )
@@ -0,0 +1,52 @@
+package geoesrishapefile
+
+import (
+	"context"
+	"github.com/raspi/GeoESRIShapeFile/common"
+	"github.com/raspi/GeoESRIShapeFile/dbf"
+	"github.com/raspi/GeoESRIShapeFile/shp"
+)
+
+/*
+Call fn for every shape and its dBase row, row is nil if there is no .dbf. Shapes whose row is
+deleted or doesn't match dBase filter are skipped. Progress is reported for .shp with total
+records from .shx header (or .dbf header if there is no .shx). progress may be nil.
+
+Stops when ctx is cancelled, returning ctx.Err(), or when reading fails or fn returns an error.
+*/
+func (sf *ShapeFiles) ForEach(ctx context.Context, fn func(n uint32, shape shp.ShapeTypeI, row map[string]dbf.Record) error, progress common.ProgressFunc) error {
+	_, hasDbf := sf.Files[`dbf`]
+
+	total := int64(-1)
+	if _, ok := sf.Files[`shx`]; ok {
+		total = int64(sf.Fshx.GetHeaderRecordCount())
+	} else if hasDbf {
+		total = int64(sf.Fdbf.Header.RecordCount)
+	}
+
+	var shpProgress common.ProgressFunc
+	if progress != nil {
+		shpProgress = func(p common.Progress) {
+			p.TotalRecords = total
+			progress(p)
+		}
+	}
+
+	return sf.Fshp.ForEach(ctx, func(n uint32, shape shp.ShapeTypeI) error {
+		var row map[string]dbf.Record
+
+		if hasDbf {
+			var err error
+			row, err = sf.Fdbf.ReadRecordAt(int(n))
+			switch err {
+			case nil:
+			case dbf.ErrorDeletedRecord, dbf.ErrorFilteredRecord:
+				return nil
+			default:
+				return err
+			}
+		}
+
+		return fn(n, shape, row)
+	}, shpProgress)
+}
@@ -0,0 +1,65 @@
+package geoesrishapefile
+
+import (
+	"context"
+	"github.com/raspi/GeoESRIShapeFile/common"
+	"github.com/raspi/GeoESRIShapeFile/dbf"
+	"github.com/raspi/GeoESRIShapeFile/shp"
+	"path/filepath"
+	"testing"
+)
+
+func TestForEach(t *testing.T) {
+	sf, err := Open(filepath.Join(`_test_files`, `point.shp`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sf.Close()
+
+	var last common.Progress
+	count := 0
+
+	err = sf.ForEach(context.Background(), func(n uint32, shape shp.ShapeTypeI, row map[string]dbf.Record) error {
+		if n != uint32(count) || shape == nil || row == nil {
+			t.Errorf(`record #%d: %v %v`, n, shape, row)
+		}
+
+		count++
+		return nil
+	}, func(p common.Progress) {
+		if p.Records != last.Records+1 || p.Bytes <= last.Bytes {
+			t.Errorf(`progress %+v after %+v`, p, last)
+		}
+
+		last = p
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if count != 3 || last.Records != 3 || last.TotalRecords != 3 || last.Bytes != last.TotalBytes {
+		t.Fatalf(`read %d records, last progress %+v`, count, last)
+	}
+}
+
+func TestForEachCancel(t *testing.T) {
+	sf, err := Open(filepath.Join(`_test_files`, `point.shp`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sf.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	count := 0
+	err = sf.ForEach(ctx, func(n uint32, shape shp.ShapeTypeI, row map[string]dbf.Record) error {
+		count++
+		cancel()
+		return nil
+	}, nil)
+
+	if err != context.Canceled || count != 1 {
+		t.Fatalf(`expected context.Canceled after 1 record, got %v after %d`, err, count)
+	}
+}
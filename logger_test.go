@@ -0,0 +1,50 @@
+package geoesrishapefile
+
+import (
+	"github.com/raspi/GeoESRIShapeFile/dbf"
+	"io"
+	"path/filepath"
+	"testing"
+)
+
+// Counts messages by level
+type countingLogger map[string]int
+
+func (cl countingLogger) Debug(msg string, args ...interface{}) { cl[`debug`]++ }
+func (cl countingLogger) Info(msg string, args ...interface{})  { cl[`info`]++ }
+func (cl countingLogger) Warn(msg string, args ...interface{})  { cl[`warn`]++ }
+func (cl countingLogger) Error(msg string, args ...interface{}) { cl[`error`]++ }
+
+func TestWithLogger(t *testing.T) {
+	cl := countingLogger{}
+
+	sf, err := New(filepath.Join(`_test_files`, `point.shp`), nil, dbf.KeepAll, dbf.DefaultConverterToString, nil, WithLogger(cl))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for {
+		_, _, err = sf.Fshp.ReadRecord()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// .shp, .shx and .dbf opened
+	if cl[`info`] != 3 {
+		t.Errorf(`expected 3 info messages, got %v`, cl)
+	}
+
+	// Headers and 3 records
+	if cl[`debug`] != 6 {
+		t.Errorf(`expected 6 debug messages, got %v`, cl)
+	}
+
+	if sf.Fshp.GetLogger() == nil || sf.Fdbf.GetLogger() == nil || sf.Fshx.GetLogger() == nil {
+		t.Errorf(`logger not passed to readers`)
+	}
+}
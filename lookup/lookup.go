@@ -0,0 +1,142 @@
+package lookup
+
+import (
+	"fmt"
+	geoesrishapefile "github.com/raspi/GeoESRIShapeFile"
+	"github.com/raspi/GeoESRIShapeFile/common"
+	"github.com/raspi/GeoESRIShapeFile/dbf"
+	"github.com/raspi/GeoESRIShapeFile/shp"
+	"golang.org/x/xerrors"
+	"io"
+)
+
+// Feature is a polygon record joined with its dBase attributes
+type Feature struct {
+	Number     uint32                // 0-based record number, same as returned by shp.ShapeFile.ReadRecord
+	Polygon    shp.Polygon           // Geometry, M and Z values are dropped
+	Attributes map[string]dbf.Record // nil if there is no .dbf
+}
+
+func (f Feature) String() string {
+	return fmt.Sprintf(`#%d %v %v`, f.Number, f.Polygon, f.Attributes)
+}
+
+// Index answers "which polygon contains this point" queries. It is read-only after New and
+// safe for concurrent use.
+type Index struct {
+	features []Feature
+	tree     *rtree
+}
+
+type UnsupportedShape struct {
+	Number uint32
+	Shape  shp.ShapeTypeI
+}
+
+func (e *UnsupportedShape) Error() string {
+	return fmt.Sprintf(`record #%d is not a polygon: %T`, e.Number, e.Shape)
+}
+
+// Load all polygons and attributes from opened shape files to memory.
+// Records with deleted or filtered (see dbf.DBaseFile.SetFilter) dBase rows and Null shapes are skipped.
+func New(sf *geoesrishapefile.ShapeFiles) (idx *Index, err error) {
+	idx = &Index{}
+	hasDbf := true
+
+	for {
+		n, shape, err := sf.Fshp.ReadRecord()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, xerrors.Errorf(`error reading shape #%d: %w`, len(idx.features), err)
+		}
+
+		var attrs map[string]dbf.Record
+
+		if hasDbf {
+			attrs, err = sf.Fdbf.ReadRecord()
+
+			switch err {
+			case nil:
+			case common.ErrorNotInitialized:
+				// No .dbf
+				hasDbf = false
+			case dbf.ErrorDeletedRecord, dbf.ErrorFilteredRecord:
+				continue
+			default:
+				return nil, xerrors.Errorf(`error reading dBase record #%d: %w`, n, err)
+			}
+		}
+
+		var p shp.Polygon
+
+		switch s := shape.(type) {
+		case shp.Null:
+			continue
+		case shp.Polygon:
+			p = s
+		case shp.PolygonM:
+			p = shp.Polygon{Box: s.Box, NumParts: s.NumParts, NumPoints: s.NumPoints, Parts: s.Parts, Points: s.Points}
+		case shp.PolygonZ:
+			p = shp.Polygon{Box: s.Box, NumParts: s.NumParts, NumPoints: s.NumPoints, Parts: s.Parts, Points: s.Points}
+		default:
+			return nil, &UnsupportedShape{Number: n, Shape: shape}
+		}
+
+		idx.features = append(idx.features, Feature{
+			Number:     n,
+			Polygon:    p,
+			Attributes: attrs,
+		})
+	}
+
+	boxes := make([]shp.Box, len(idx.features))
+	for i, f := range idx.features {
+		boxes[i] = f.Polygon.Box
+	}
+
+	idx.tree = newRtree(boxes)
+
+	return idx, nil
+}
+
+// How many polygons are indexed
+func (idx *Index) Len() int {
+	return len(idx.features)
+}
+
+// Find first polygon containing point. With overlapping polygons the lowest record number is not guaranteed, see LookupAll.
+func (idx *Index) Lookup(pt shp.Point) (f *Feature, ok bool) {
+	idx.tree.search(pt, func(i int) bool {
+		if idx.features[i].Polygon.Contains(pt) {
+			f = &idx.features[i]
+			return false
+		}
+
+		return true
+	})
+
+	return f, f != nil
+}
+
+// Find all polygons containing point, sorted by record number
+func (idx *Index) LookupAll(pt shp.Point) (found []*Feature) {
+	idx.tree.search(pt, func(i int) bool {
+		if idx.features[i].Polygon.Contains(pt) {
+			found = append(found, &idx.features[i])
+		}
+
+		return true
+	})
+
+	// Insertion sort, there are rarely more than a couple of results
+	for i := 1; i < len(found); i++ {
+		for j := i; j > 0 && found[j].Number < found[j-1].Number; j-- {
+			found[j], found[j-1] = found[j-1], found[j]
+		}
+	}
+
+	return found
+}
@@ -0,0 +1,220 @@
+package lookup
+
+import (
+	"bytes"
+	"encoding/binary"
+	geoesrishapefile "github.com/raspi/GeoESRIShapeFile"
+	"github.com/raspi/GeoESRIShapeFile/common"
+	"github.com/raspi/GeoESRIShapeFile/shp"
+	"math"
+	"sort"
+	"testing"
+	"testing/fstest"
+)
+
+func TestRtreeSearch(t *testing.T) {
+	var boxes []shp.Box
+	for x := 0; x < 50; x++ {
+		for y := 0; y < 50; y++ {
+			boxes = append(boxes, shp.Box{MinX: float64(x), MinY: float64(y), MaxX: float64(x) + 1.5, MaxY: float64(y) + 1.5})
+		}
+	}
+
+	tree := newRtree(boxes)
+
+	for _, pt := range []shp.Point{{X: 0.5, Y: 0.5}, {X: 10.2, Y: 20.7}, {X: 49.9, Y: 0.1}, {X: 25, Y: 25}, {X: -1, Y: 3}, {X: 100, Y: 100}} {
+		var expected, actual []int
+		for idx, b := range boxes {
+			if b.Contains(pt) {
+				expected = append(expected, idx)
+			}
+		}
+
+		tree.search(pt, func(idx int) bool {
+			actual = append(actual, idx)
+			return true
+		})
+
+		sort.Ints(actual)
+
+		if len(actual) != len(expected) {
+			t.Fatalf(`point %v found %v, should be %v`, pt, actual, expected)
+		}
+
+		for i := range actual {
+			if actual[i] != expected[i] {
+				t.Fatalf(`point %v found %v, should be %v`, pt, actual, expected)
+			}
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	sf, err := geoesrishapefile.Open(`../_test_files/polygon.shp`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sf.Close()
+
+	idx, err := New(&sf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if idx.Len() != 1 {
+		t.Fatalf(`indexed %d polygons, should be 1`, idx.Len())
+	}
+
+	f, ok := idx.Lookup(shp.Point{X: 2, Y: 3})
+	if !ok {
+		t.Fatal(`polygon not found`)
+	}
+
+	if f.Number != 0 {
+		t.Fatalf(`found %v, should be #0`, f)
+	}
+
+	if _, ok := f.Attributes[`AREA`]; !ok {
+		t.Fatalf(`found %v without AREA attribute`, f)
+	}
+
+	_, ok = idx.Lookup(shp.Point{X: 6, Y: 3})
+	if ok {
+		t.Fatal(`point outside polygon was found`)
+	}
+}
+
+// Ring from x, y pairs
+func ring(xy ...float64) (points []shp.Point) {
+	for i := 0; i+1 < len(xy); i += 2 {
+		points = append(points, shp.Point{X: xy[i], Y: xy[i+1]})
+	}
+
+	return points
+}
+
+// Open dataset which has only .shp with given polygons, each polygon is a list of rings
+func polygonDataset(t *testing.T, polygons [][][]shp.Point) geoesrishapefile.ShapeFiles {
+	fileBox := shp.Box{MinX: math.Inf(1), MinY: math.Inf(1), MaxX: math.Inf(-1), MaxY: math.Inf(-1)}
+	var records bytes.Buffer
+
+	for n, rings := range polygons {
+		box := shp.Box{MinX: math.Inf(1), MinY: math.Inf(1), MaxX: math.Inf(-1), MaxY: math.Inf(-1)}
+		var parts []uint32
+		var points []shp.Point
+
+		for _, ring := range rings {
+			parts = append(parts, uint32(len(points)))
+			points = append(points, ring...)
+
+			for _, pt := range ring {
+				box = shp.Box{MinX: math.Min(box.MinX, pt.X), MinY: math.Min(box.MinY, pt.Y), MaxX: math.Max(box.MaxX, pt.X), MaxY: math.Max(box.MaxY, pt.Y)}
+			}
+		}
+
+		fileBox = shp.Box{MinX: math.Min(fileBox.MinX, box.MinX), MinY: math.Min(fileBox.MinY, box.MinY), MaxX: math.Max(fileBox.MaxX, box.MaxX), MaxY: math.Max(fileBox.MaxY, box.MaxY)}
+
+		var content bytes.Buffer
+		// Writes to bytes.Buffer don't fail
+		_ = binary.Write(&content, binary.LittleEndian, common.POLYGON)
+		_ = binary.Write(&content, binary.LittleEndian, box)
+		_ = binary.Write(&content, binary.LittleEndian, [2]uint32{uint32(len(parts)), uint32(len(points))})
+		_ = binary.Write(&content, binary.LittleEndian, parts)
+		_ = binary.Write(&content, binary.LittleEndian, points)
+
+		_ = binary.Write(&records, binary.BigEndian, [2]uint32{uint32(n + 1), uint32(content.Len() / 2)})
+		records.Write(content.Bytes())
+	}
+
+	hdr1 := common.ShapeFileHeader1{FileCode: common.HeaderFileCode, Length: uint32(100+records.Len()) / 2}
+	hdr2 := common.ShapeFileHeader2{Version: common.SecondaryHeaderVersion, ShapeType: common.POLYGON}
+	hdr2.Min.X, hdr2.Min.Y, hdr2.Max.X, hdr2.Max.Y = fileBox.MinX, fileBox.MinY, fileBox.MaxX, fileBox.MaxY
+
+	var data bytes.Buffer
+	_ = binary.Write(&data, binary.BigEndian, hdr1)
+	_ = binary.Write(&data, binary.LittleEndian, hdr2)
+	data.Write(records.Bytes())
+
+	sf, err := geoesrishapefile.OpenFS(fstest.MapFS{`test.shp`: &fstest.MapFile{Data: data.Bytes()}}, `test.shp`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return sf
+}
+
+func TestLookupPolygons(t *testing.T) {
+	sf := polygonDataset(t, [][][]shp.Point{
+		// #0 square with hole
+		{
+			ring(0, 0, 0, 10, 10, 10, 10, 0, 0, 0),
+			ring(4, 4, 6, 4, 6, 6, 4, 6, 4, 4),
+		},
+		// #1 two separate squares
+		{
+			ring(20, 0, 20, 2, 22, 2, 22, 0, 20, 0),
+			ring(28, 0, 28, 2, 30, 2, 30, 0, 28, 0),
+		},
+		// #2 and #3 triangles with overlapping boxes but not overlapping each other
+		{
+			ring(40, 0, 40, 10, 50, 0, 40, 0),
+		},
+		{
+			ring(42, 10, 50, 10, 50, 2, 42, 10),
+		},
+		// #4 square overlapping #2
+		{
+			ring(40, 0, 40, 4, 44, 4, 44, 0, 40, 0),
+		},
+	})
+	defer sf.Close()
+
+	idx, err := New(&sf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if idx.Len() != 5 {
+		t.Fatalf(`indexed %d polygons, should be 5`, idx.Len())
+	}
+
+	tests := []struct {
+		pt       shp.Point
+		expected []uint32
+	}{
+		{shp.Point{X: 1, Y: 1}, []uint32{0}},
+		{shp.Point{X: 5, Y: 5}, nil}, // hole
+		{shp.Point{X: 21, Y: 1}, []uint32{1}},
+		{shp.Point{X: 29, Y: 1}, []uint32{1}},
+		{shp.Point{X: 25, Y: 1}, nil}, // between parts, inside box
+		{shp.Point{X: 48, Y: 8}, []uint32{3}},
+		{shp.Point{X: 46, Y: 5}, nil}, // inside boxes of #2 and #3, outside both triangles
+		{shp.Point{X: 41, Y: 1}, []uint32{2, 4}},
+	}
+
+	for _, tt := range tests {
+		var actual []uint32
+		for _, f := range idx.LookupAll(tt.pt) {
+			actual = append(actual, f.Number)
+		}
+
+		if len(actual) != len(tt.expected) {
+			t.Fatalf(`point %v found %v, should be %v`, tt.pt, actual, tt.expected)
+		}
+
+		for i := range actual {
+			if actual[i] != tt.expected[i] {
+				t.Fatalf(`point %v found %v, should be %v`, tt.pt, actual, tt.expected)
+			}
+		}
+
+		f, ok := idx.Lookup(tt.pt)
+		if ok != (len(tt.expected) > 0) {
+			t.Fatalf(`point %v: Lookup returned %v, %v`, tt.pt, f, ok)
+		}
+
+		if ok && f.Attributes != nil {
+			t.Fatalf(`point %v: found %v with attributes, there is no .dbf`, tt.pt, f)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package lookup
+
+import (
+	"github.com/raspi/GeoESRIShapeFile/shp"
+	"math"
+	"sort"
+)
+
+const rtreeNodeSize = 16
+
+// Node covers a range of nodes in the level below. In leaf level the range is a single feature index.
+type rtreeNode struct {
+	box        shp.Box
+	start, end int
+}
+
+// Static R-tree packed with Sort-Tile-Recursive. Built once, read-only after that.
+type rtree struct {
+	levels [][]rtreeNode // levels[0] is leaves, last level is root
+}
+
+func newRtree(boxes []shp.Box) *rtree {
+	t := &rtree{}
+
+	level := make([]rtreeNode, len(boxes))
+	for idx, b := range boxes {
+		level[idx] = rtreeNode{box: b, start: idx, end: idx + 1}
+	}
+
+	for {
+		strSort(level)
+		t.levels = append(t.levels, level)
+
+		if len(level) <= 1 {
+			break
+		}
+
+		parents := make([]rtreeNode, 0, (len(level)+rtreeNodeSize-1)/rtreeNodeSize)
+		for start := 0; start < len(level); start += rtreeNodeSize {
+			end := start + rtreeNodeSize
+			if end > len(level) {
+				end = len(level)
+			}
+
+			box := level[start].box
+			for _, n := range level[start+1 : end] {
+				box = union(box, n.box)
+			}
+
+			parents = append(parents, rtreeNode{box: box, start: start, end: end})
+		}
+
+		level = parents
+	}
+
+	return t
+}
+
+// Sort nodes to vertical slices by X center and each slice by Y center
+func strSort(nodes []rtreeNode) {
+	if len(nodes) <= rtreeNodeSize {
+		return
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].box.MinX+nodes[i].box.MaxX < nodes[j].box.MinX+nodes[j].box.MaxX
+	})
+
+	leafCount := (len(nodes) + rtreeNodeSize - 1) / rtreeNodeSize
+	sliceSize := int(math.Ceil(math.Sqrt(float64(leafCount)))) * rtreeNodeSize
+
+	for start := 0; start < len(nodes); start += sliceSize {
+		end := start + sliceSize
+		if end > len(nodes) {
+			end = len(nodes)
+		}
+
+		s := nodes[start:end]
+		sort.Slice(s, func(i, j int) bool {
+			return s[i].box.MinY+s[i].box.MaxY < s[j].box.MinY+s[j].box.MaxY
+		})
+	}
+}
+
+// Call fn for each feature index whose box contains point. Stops if fn returns false.
+func (t *rtree) search(pt shp.Point, fn func(idx int) bool) {
+	if len(t.levels) == 0 || len(t.levels[0]) == 0 {
+		return
+	}
+
+	t.searchLevel(len(t.levels)-1, 0, len(t.levels[len(t.levels)-1]), pt, fn)
+}
+
+func (t *rtree) searchLevel(level, start, end int, pt shp.Point, fn func(idx int) bool) bool {
+	for _, n := range t.levels[level][start:end] {
+		if !n.box.Contains(pt) {
+			continue
+		}
+
+		if level == 0 {
+			if !fn(n.start) {
+				return false
+			}
+
+			continue
+		}
+
+		if !t.searchLevel(level-1, n.start, n.end, pt, fn) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func union(a, b shp.Box) shp.Box {
+	return shp.Box{
+		MinX: math.Min(a.MinX, b.MinX),
+		MinY: math.Min(a.MinY, b.MinY),
+		MaxX: math.Max(a.MaxX, b.MaxX),
+		MaxY: math.Max(a.MaxY, b.MaxY),
+	}
+}
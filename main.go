@@ -1,11 +1,13 @@
 package geoesrishapefile
 
 import (
+	"github.com/raspi/GeoESRIShapeFile/common"
 	"github.com/raspi/GeoESRIShapeFile/dbf"
+	"github.com/raspi/GeoESRIShapeFile/sbn"
 	"github.com/raspi/GeoESRIShapeFile/shp"
 	"github.com/raspi/GeoESRIShapeFile/shx"
-	"io/ioutil"
-	"log"
+	"golang.org/x/text/encoding"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
@@ -16,26 +18,64 @@ type ShapeFiles struct {
 	Fshx shx.IndexRecordLookupFile // lookups
 	Fdbf dbf.DBaseFile
 
-	// logging
-	debug struct {
-		shp  bool
-		shx  bool
-		dbf  bool
-		self bool
-		all  bool
+	// Optional ArcGIS spatial bin index, nil if not present
+	Fsbn *sbn.SpatialBinFile
+	Fsbx *sbn.BinIndexFile
+
+	// All files sharing the base name, lower case extension without dot -> path. Includes sidecars
+	// this library doesn't read, such as .prj and .cpg
+	Files map[string]string
+
+	logger     common.Logger // nil for no logging
+	dbfOptions []dbf.Option
+}
+
+// Option changes how files are opened, see Open
+type Option func(sf *ShapeFiles)
+
+// Log with given logger, for example *slog.Logger. Files opened are logged at info level and
+// every record read at debug level. Default is no logging.
+func WithLogger(l common.Logger) Option {
+	return func(sf *ShapeFiles) {
+		sf.logger = l
 	}
 }
 
-func New(fpath string, parseFieldNames []string, parseFieldNamesOperation dbf.Operation, defaultConverter dbf.ConverterFunction, converters map[string]dbf.ConverterFunction) (sf ShapeFiles, err error) {
-	sf.debug.all = true
+// Read only listed dBase fields
+func WithFields(names ...string) Option {
+	return withDbf(dbf.WithFields(names...))
+}
+
+// Read all dBase fields except listed
+func WithoutFields(names ...string) Option {
+	return withDbf(dbf.WithoutFields(names...))
+}
+
+// Convert dBase field with given function instead of default converter
+func WithConverter(field string, fn dbf.ConverterFunction) Option {
+	return withDbf(dbf.WithConverter(field, fn))
+}
 
-	if sf.debug.all {
-		sf.debug.self = true
-		sf.debug.shp = true
-		sf.debug.shx = true
-		sf.debug.dbf = true
+// Converter for dBase fields without their own converter. Default is dbf.DefaultConverterToString.
+func WithDefaultConverter(fn dbf.ConverterFunction) Option {
+	return withDbf(dbf.WithDefaultConverter(fn))
+}
+
+// Decode dBase character fields from given encoding to UTF-8, for example charmap.Windows1252
+// from golang.org/x/text/encoding/charmap. Default is to pass bytes as-is.
+func WithEncoding(enc encoding.Encoding) Option {
+	return withDbf(dbf.WithEncoding(enc))
+}
+
+func withDbf(opt dbf.Option) Option {
+	return func(sf *ShapeFiles) {
+		sf.dbfOptions = append(sf.dbfOptions, opt)
 	}
+}
 
+// Open .shp, .shx, .dbf, .sbn and .sbx files sharing base name of fpath. Use Close to close them.
+// Spatial bin index is optional: if .sbn or .sbx can't be read, it is logged and Fsbn or Fsbx is left nil.
+func Open(fpath string, opts ...Option) (sf ShapeFiles, err error) {
 	fpath, err = filepath.Abs(fpath)
 	if err != nil {
 		return sf, err
@@ -46,114 +86,223 @@ func New(fpath string, parseFieldNames []string, parseFieldNamesOperation dbf.Op
 		return sf, err
 	}
 
-	origdir, origfname := filepath.Split(fpath)
-	origext := filepath.Ext(origfname)
-	origFnameNoExt := strings.TrimRight(origfname, origext)
+	return open(osSource{}, fpath, opts)
+}
+
+// Open files from src sharing base name of fpath
+func open(src source, fpath string, opts []Option) (sf ShapeFiles, err error) {
+	for _, opt := range opts {
+		opt(&sf)
+	}
+
+	defer func() {
+		if err != nil {
+			// Don't leak files opened before the error
+			_ = sf.Close()
+		}
+	}()
+
+	origdir, origfname := src.split(fpath)
+	origFnameNoExt := trimDatasetExt(origfname)
 
-	flist, err := ioutil.ReadDir(origdir)
+	flist, err := src.readDir(origdir)
 	if err != nil {
 		return sf, err
 	}
 
-	for _, f := range flist {
-		if f.IsDir() {
+	for _, name := range flist {
+		ext := filepath.Ext(name)
+		fname := strings.TrimSuffix(name, ext)
+		ext = strings.TrimLeft(ext, `.`)
+
+		if fname != origFnameNoExt {
 			continue
 		}
 
-		dir, fname, ext := fnamesplit(filepath.Join(origdir, f.Name()))
+		ofile := src.join(origdir, name)
 
-		if fname != origFnameNoExt {
+		if sf.Files == nil {
+			sf.Files = make(map[string]string)
+		}
+
+		sf.Files[strings.ToLower(ext)] = ofile
+
+		switch strings.ToLower(ext) {
+		case `dbf`, `shp`, `shx`, `sbn`, `sbx`:
+		default:
 			continue
 		}
 
-		ofile := filepath.Join(dir, fname) + "." + ext
+		sf.logLoading(ofile)
+
+		f, err := src.open(ofile)
+		if err != nil {
+			return sf, err
+		}
 
 		switch strings.ToLower(ext) {
 		case `dbf`: // dBase Database
-			err = sf.loadDbf(ofile, parseFieldNames, parseFieldNamesOperation, defaultConverter, converters)
-			if err != nil {
-				return sf, err
-			}
+			err = sf.loadDbf(f)
 		case `shp`: // ShapeFile
-			err = sf.loadShp(ofile)
-			if err != nil {
-				return sf, err
-			}
+			err = sf.loadShp(f)
 		case `shx`: // ShapeFile Index Offsets
-			err = sf.loadShx(ofile)
-			if err != nil {
-				return sf, err
-			}
+			err = sf.loadShx(f)
+		case `sbn`: // Spatial Bin Index (ArcGIS)
+			err = sf.loadSbn(f)
+		case `sbx`: // Spatial Bin Index Offsets (ArcGIS)
+			err = sf.loadSbx(f)
+		}
 
-		default:
-			continue
+		if err != nil {
+			return sf, err
 		}
 	}
 
+	if sf.Files == nil {
+		return sf, &fs.PathError{Op: `open`, Path: fpath, Err: fs.ErrNotExist}
+	}
+
 	return sf, nil
 }
 
-func fnamesplit(fpath string) (dir, fname, ext string) {
-	dir, fname = filepath.Split(fpath)
-	ext = filepath.Ext(fname)
-	fnameNoExt := strings.TrimRight(fname, ext)
-	ext = strings.TrimLeft(ext, `.`)
-	return dir, fnameNoExt, ext
+// Remove .shp, .shx, .dbf, .sbn or .sbx extension in any case. Other dots are part of base name, for example roads.v2.
+func trimDatasetExt(fname string) string {
+	ext := filepath.Ext(fname)
+
+	switch strings.ToLower(ext) {
+	case `.shp`, `.shx`, `.dbf`, `.sbn`, `.sbx`:
+		return strings.TrimSuffix(fname, ext)
+	}
+
+	return fname
 }
 
-func (sf *ShapeFiles) loadShp(fname string) (err error) {
-	if sf.debug.shp {
-		log.Printf(`loading .shp file %v`, fname)
+// Same as Open with WithFields or WithoutFields, WithDefaultConverter and WithConverter for each converter
+func New(fpath string, parseFieldNames []string, parseFieldNamesOperation dbf.Operation, defaultConverter dbf.ConverterFunction, converters map[string]dbf.ConverterFunction, opts ...Option) (sf ShapeFiles, err error) {
+	var dbfOpts []Option
+
+	switch parseFieldNamesOperation {
+	case dbf.KeepOnlyListed:
+		dbfOpts = append(dbfOpts, WithFields(parseFieldNames...))
+	case dbf.SkipThese:
+		dbfOpts = append(dbfOpts, WithoutFields(parseFieldNames...))
 	}
 
-	sf.Fshp, err = shp.New(fname)
-	if err != nil {
-		return err
+	dbfOpts = append(dbfOpts, WithDefaultConverter(defaultConverter))
+
+	for field, fn := range converters {
+		dbfOpts = append(dbfOpts, WithConverter(field, fn))
 	}
 
-	sf.Fshp.SetDebug(sf.debug.shp)
+	return Open(fpath, append(dbfOpts, opts...)...)
+}
+
+// Close all opened files. Returns first error, but tries to close every file.
+func (sf *ShapeFiles) Close() (err error) {
+	closers := []interface{ Close() error }{&sf.Fshp, &sf.Fshx, &sf.Fdbf, sf.Fsbn, sf.Fsbx}
 
-	err = sf.Fshp.Initialize()
-	if err != nil {
-		return err
+	for _, c := range closers {
+		cerr := c.Close()
+		if cerr != nil && err == nil {
+			err = cerr
+		}
 	}
 
-	return nil
+	return err
 }
-func (sf *ShapeFiles) loadShx(fname string) (err error) {
-	if sf.debug.shx {
-		log.Printf(`loading .shx file %v`, fname)
+
+// Set lenient mode for .shp reading, see shp.ShapeFile SetLenient. If .shx was loaded, it is used
+// to find the record after a corrupt one, which moves .shx read position.
+func (sf *ShapeFiles) SetLenient(flag bool) {
+	var index shp.RecordIndex
+	if _, ok := sf.Files[`shx`]; ok {
+		index = &sf.Fshx
 	}
 
-	sf.Fshx, err = shx.New(fname)
-	if err != nil {
-		return err
+	sf.Fshp.SetLenient(flag, index)
+}
+
+// Set logger of all loaded files, nil disables logging
+func (sf *ShapeFiles) SetLogger(l common.Logger) {
+	sf.logger = l
+
+	sf.Fshp.SetLogger(l)
+	sf.Fshx.SetLogger(l)
+	sf.Fdbf.SetLogger(l)
+
+	if sf.Fsbn != nil {
+		sf.Fsbn.SetLogger(l)
 	}
-	sf.Fshx.SetDebug(sf.debug.shx)
 
-	err = sf.Fshx.Initialize()
-	if err != nil {
-		return err
+	if sf.Fsbx != nil {
+		sf.Fsbx.SetLogger(l)
 	}
-	return nil
 }
 
-func (sf *ShapeFiles) loadDbf(fname string, parseFieldNames []string, parseFieldNamesOperation dbf.Operation, defaultConverter dbf.ConverterFunction, converters map[string]dbf.ConverterFunction) (err error) {
-	if sf.debug.dbf {
-		log.Printf(`loading .dbf file %v`, fname)
+func (sf *ShapeFiles) logLoading(fname string) {
+	if sf.logger != nil {
+		sf.logger.Info(`loading file`, `path`, fname)
 	}
+}
+
+func (sf *ShapeFiles) loadShp(f common.ReadSeekCloser) (err error) {
+	sf.Fshp = shp.NewFrom(f)
+	sf.Fshp.SetLogger(sf.logger)
 
-	sf.Fdbf, err = dbf.New(fname, parseFieldNames, parseFieldNamesOperation, defaultConverter, converters)
+	return sf.Fshp.Initialize()
+}
+
+func (sf *ShapeFiles) loadShx(f common.ReadSeekCloser) (err error) {
+	sf.Fshx = shx.NewFrom(f)
+	sf.Fshx.SetLogger(sf.logger)
+
+	return sf.Fshx.Initialize()
+}
+
+func (sf *ShapeFiles) loadDbf(f common.ReadSeekCloser) (err error) {
+	sf.Fdbf = dbf.OpenFrom(f, sf.dbfOptions...)
+	sf.Fdbf.SetLogger(sf.logger)
+
+	return sf.Fdbf.Initialize()
+}
+
+// Spatial bin index is optional, so a broken one is logged and left out instead of failing
+func (sf *ShapeFiles) loadSbn(f common.ReadSeekCloser) (err error) {
+	sbnf := sbn.NewFrom(f)
+	sbnf.SetLogger(sf.logger)
+
+	err = sbnf.Initialize()
 	if err != nil {
-		return err
+		sf.optionalFailed(`sbn`, f, err)
+		return nil
 	}
 
-	sf.Fdbf.SetDebug(sf.debug.dbf)
+	sf.Fsbn = &sbnf
 
-	err = sf.Fdbf.Initialize()
+	return nil
+}
+
+// See loadSbn
+func (sf *ShapeFiles) loadSbx(f common.ReadSeekCloser) (err error) {
+	sbxf := sbn.NewIndexFrom(f)
+	sbxf.SetLogger(sf.logger)
+
+	err = sbxf.Initialize()
 	if err != nil {
-		return err
+		sf.optionalFailed(`sbx`, f, err)
+		return nil
 	}
 
+	sf.Fsbx = &sbxf
+
 	return nil
 }
+
+// Log and close optional file which couldn't be loaded
+func (sf *ShapeFiles) optionalFailed(ext string, f common.ReadSeekCloser, err error) {
+	if sf.logger != nil {
+		sf.logger.Warn(`skipped optional file`, `path`, sf.Files[ext], `error`, err)
+	}
+
+	_ = f.Close()
+}
@@ -0,0 +1,42 @@
+package geoesrishapefile
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestOpenClose(t *testing.T) {
+	sf, err := Open(filepath.Join(`_test_files`, `point.shp`), WithoutFields(`point_ID`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	row, err := sf.Fdbf.ReadRecord()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(row) != 0 {
+		t.Errorf(`point_ID should be skipped, got %v`, row)
+	}
+
+	err = sf.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err = sf.Fshp.ReadRecord()
+	if err == nil {
+		t.Fatal(`read from closed file succeeded`)
+	}
+}
+
+// Files which weren't found are not closed
+func TestCloseNotOpened(t *testing.T) {
+	var sf ShapeFiles
+
+	err := sf.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
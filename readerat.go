@@ -0,0 +1,78 @@
+package geoesrishapefile
+
+import (
+	"errors"
+	"github.com/raspi/GeoESRIShapeFile/common"
+	"github.com/raspi/GeoESRIShapeFile/shp"
+	"io"
+	"io/fs"
+	"sort"
+)
+
+var ErrorNoIndex = errors.New(`no .shx index`)
+
+/*
+Open dataset from io.ReaderAt files, for example objects in remote storage read with HTTP range
+requests. Use io.NewSectionReader(r, 0, size) for each file. shx and dbf may be nil. name is base
+name without extension, used in Files and log messages.
+
+Every read is one ReadAt call, see ReadShapeAt for reading a record with one ranged read.
+*/
+func OpenReaderAt(name string, shpFile, shxFile, dbfFile *io.SectionReader, opts ...Option) (ShapeFiles, error) {
+	src := readerAtSource{}
+
+	for ext, r := range map[string]*io.SectionReader{`shp`: shpFile, `shx`: shxFile, `dbf`: dbfFile} {
+		if r != nil {
+			src[name+`.`+ext] = r
+		}
+	}
+
+	return open(src, name, opts)
+}
+
+// Read n:th (0-based) shape by looking up its offset and length from .shx. Reads one .shx entry
+// and then the whole record with one read, see shp.ShapeFile ReadIndexedRecord.
+func (sf *ShapeFiles) ReadShapeAt(n uint32) (shape shp.ShapeTypeI, err error) {
+	if _, ok := sf.Files[`shx`]; !ok {
+		return nil, ErrorNoIndex
+	}
+
+	e, err := sf.Fshx.ReadRecordAt(uint(n))
+	if err != nil {
+		return nil, err
+	}
+
+	_, shape, err = sf.Fshp.ReadIndexedRecord(e.Offset, e.Length)
+	return shape, err
+}
+
+// Files given to OpenReaderAt by name
+type readerAtSource map[string]*io.SectionReader
+
+func (s readerAtSource) readDir(dir string) (names []string, err error) {
+	for name := range s {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names, nil
+}
+
+func (s readerAtSource) open(name string) (common.ReadSeekCloser, error) {
+	r, ok := s[name]
+	if !ok {
+		return nil, &fs.PathError{Op: `open`, Path: name, Err: fs.ErrNotExist}
+	}
+
+	return common.NewReaderAt(r, r.Size()), nil
+}
+
+// Names may be URLs or object keys, so they are not split to directory and file
+func (readerAtSource) split(fpath string) (string, string) {
+	return ``, fpath
+}
+
+func (readerAtSource) join(dir, fname string) string {
+	return fname
+}
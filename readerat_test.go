@@ -0,0 +1,135 @@
+package geoesrishapefile
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+// Reads file from HTTP server with range requests, like a client of object storage would
+type httpReaderAt struct {
+	url string
+}
+
+func (h httpReaderAt) ReadAt(p []byte, off int64) (n int, err error) {
+	req, err := http.NewRequest(http.MethodGet, h.url, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set(`Range`, fmt.Sprintf(`bytes=%d-%d`, off, off+int64(len(p))-1))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+		return 0, io.EOF
+	}
+
+	if resp.StatusCode != http.StatusPartialContent {
+		return 0, fmt.Errorf(`%v: %v`, h.url, resp.Status)
+	}
+
+	n, err = io.ReadFull(resp.Body, p)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+
+	return n, err
+}
+
+// Open dataset from httptest server, requests counts range requests made
+func openHTTP(t *testing.T, name string) (sf ShapeFiles, requests *int64) {
+	requests = new(int64)
+	files := http.FileServer(http.Dir(`_test_files`))
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(requests, 1)
+		files.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	sizes := map[string]int64{}
+	for _, ext := range []string{`shp`, `shx`, `dbf`} {
+		resp, err := http.Head(srv.URL + `/` + name + `.` + ext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		sizes[ext] = resp.ContentLength
+	}
+
+	section := func(ext string) *io.SectionReader {
+		return io.NewSectionReader(httpReaderAt{url: srv.URL + `/` + name + `.` + ext}, 0, sizes[ext])
+	}
+
+	sf, err := OpenReaderAt(srv.URL+`/`+name, section(`shp`), section(`shx`), section(`dbf`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sf.Close() })
+
+	return sf, requests
+}
+
+func TestOpenReaderAt(t *testing.T) {
+	remote, requests := openHTTP(t, `polygonz`)
+
+	local, err := Open(filepath.Join(`_test_files`, `polygonz.shp`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer local.Close()
+
+	for n := uint32(0); n < uint32(local.Fshx.GetHeaderRecordCount()); n++ {
+		want, err := local.ReadShapeAt(n)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		before := atomic.LoadInt64(requests)
+
+		got, err := remote.ReadShapeAt(n)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// .shx entry and whole record
+		if reqs := atomic.LoadInt64(requests) - before; reqs != 2 {
+			t.Errorf(`record #%d took %d requests, should be 2`, n, reqs)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf(`record #%d is %v, should be %v`, n, got, want)
+		}
+
+		before = atomic.LoadInt64(requests)
+
+		_, err = remote.Fdbf.ReadRecordAt(int(n))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if reqs := atomic.LoadInt64(requests) - before; reqs != 1 {
+			t.Errorf(`row #%d took %d requests, should be 1`, n, reqs)
+		}
+	}
+}
+
+func TestReadShapeAtNoIndex(t *testing.T) {
+	var sf ShapeFiles
+
+	_, err := sf.ReadShapeAt(0)
+	if err != ErrorNoIndex {
+		t.Fatalf(`expected ErrorNoIndex, got %v`, err)
+	}
+}
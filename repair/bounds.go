@@ -0,0 +1,100 @@
+package repair
+
+import (
+	"github.com/raspi/GeoESRIShapeFile/shp"
+	"math"
+)
+
+// M values less than this are "no data" in ESRI shapefile specification
+const noDataM = -1e38
+
+// Min and max of one dimension
+type extent struct {
+	Min, Max float64
+	ok       bool
+}
+
+func (e *extent) add(v float64) {
+	if math.IsNaN(v) {
+		return
+	}
+
+	if !e.ok {
+		e.Min, e.Max, e.ok = v, v, true
+		return
+	}
+
+	e.Min = math.Min(e.Min, v)
+	e.Max = math.Max(e.Max, v)
+}
+
+func (e *extent) addM(values ...float64) {
+	for _, v := range values {
+		if v >= noDataM {
+			e.add(v)
+		}
+	}
+}
+
+// Bounds of all records, calculated from points instead of record boxes
+type bounds struct {
+	X, Y, Z, M extent
+}
+
+func (b *bounds) addPoints(points []shp.Point) {
+	for _, p := range points {
+		b.X.add(p.X)
+		b.Y.add(p.Y)
+	}
+}
+
+func (b *bounds) addZ(values ...float64) {
+	for _, v := range values {
+		b.Z.add(v)
+	}
+}
+
+func (b *bounds) addShape(shape shp.ShapeTypeI) {
+	switch s := shape.(type) {
+	case shp.Point:
+		b.addPoints([]shp.Point{s})
+	case shp.PointM:
+		b.addPoints([]shp.Point{{X: s.X, Y: s.Y}})
+		b.M.addM(s.M)
+	case shp.PointZ:
+		b.addPoints([]shp.Point{{X: s.X, Y: s.Y}})
+		b.addZ(s.Z)
+		b.M.addM(s.M)
+	case shp.MultiPoint:
+		b.addPoints(s.Points)
+	case shp.MultiPointM:
+		b.addPoints(s.Points)
+		b.M.addM(s.MArray...)
+	case shp.MultiPointZ:
+		b.addPoints(s.Points)
+		b.addZ(s.ZArray...)
+		b.M.addM(s.MArray...)
+	case shp.PolyLine:
+		b.addPoints(s.Points)
+	case shp.PolyLineM:
+		b.addPoints(s.Points)
+		b.M.addM(s.MArray...)
+	case shp.PolyLineZ:
+		b.addPoints(s.Points)
+		b.addZ(s.ZArray...)
+		b.M.addM(s.MArray...)
+	case shp.Polygon:
+		b.addPoints(s.Points)
+	case shp.PolygonM:
+		b.addPoints(s.Points)
+		b.M.addM(s.MArray...)
+	case shp.PolygonZ:
+		b.addPoints(s.Points)
+		b.addZ(s.ZArray...)
+		b.M.addM(s.MArray...)
+	case shp.MultiPatch:
+		b.addPoints(s.Points)
+		b.addZ(s.ZArray...)
+		b.M.addM(s.MArray...)
+	}
+}
@@ -0,0 +1,349 @@
+package repair
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"github.com/raspi/GeoESRIShapeFile/common"
+	"github.com/raspi/GeoESRIShapeFile/shp"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	headerSize         = 100
+	headerLengthOffset = 24
+	headerBoxOffset    = 36
+	headerZOffset      = 68
+	headerMOffset      = 84
+	recordHeaderSize   = 8
+	shxEntrySize       = 8
+)
+
+type Options struct {
+	Truncate bool // Cut partial record from end of .shp
+	DryRun   bool // Don't write anything, only report what would be changed
+}
+
+// Change made (or with DryRun, would be made) to a file
+type Change struct {
+	File    string `json:"file"`   // Extension without dot
+	Offset  int64  `json:"offset"` // Byte offset of changed data, -1 if whole file was written
+	Message string `json:"message"`
+}
+
+func (c Change) String() string {
+	if c.Offset < 0 {
+		return fmt.Sprintf(`%v: %v`, c.File, c.Message)
+	}
+
+	return fmt.Sprintf(`%v offset 0x%04[2]x (%06[2]d): %[3]v`, c.File, c.Offset, c.Message)
+}
+
+type Result struct {
+	Path     string   `json:"path"`     // .shp file
+	Records  int      `json:"records"`  // Complete records found in .shp
+	Changes  []Change `json:"changes"`  // Empty if nothing needed to be changed
+	Warnings []string `json:"warnings"` // Problems which were found but not repaired
+}
+
+func (r *Result) change(file string, offset int64, format string, args ...interface{}) {
+	r.Changes = append(r.Changes, Change{File: file, Offset: offset, Message: fmt.Sprintf(format, args...)})
+}
+
+func (r *Result) warn(format string, args ...interface{}) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+}
+
+// Record location found by scanning .shp
+type record struct {
+	offset int64
+	length int64 // Content length in bytes
+}
+
+/*
+Repair dataset by scanning .shp record headers:
+
+  - rebuild .shx from record offsets and lengths, it is created if missing
+  - set .shp header file length to end of last valid record
+  - recalculate .shp and .shx header bounding box and Z and M ranges from record points
+  - with Options.Truncate cut partial record from end of .shp
+
+Records themselves are not modified, so record bounding boxes are not recalculated, use validate
+package to find wrong ones. Scan stops at first record which runs past end of file, has number 0,
+has content too short for shape type or has shape type other than NULL or header shape type. Rest
+of file from there is handled as partial record, for example zero padding after last record.
+Header file code, version and shape type must be valid.
+*/
+func Dataset(fpath string, opts Options) (res Result, err error) {
+	res.Path = fpath
+
+	flag := os.O_RDWR
+	if opts.DryRun {
+		flag = os.O_RDONLY
+	}
+
+	f, err := os.OpenFile(fpath, flag, 0)
+	if err != nil {
+		return res, err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return res, err
+	}
+
+	size := fi.Size()
+
+	hdr1, hdr2, err := readHeader(f)
+	if err != nil {
+		return res, err
+	}
+
+	records, b, end, err := scan(f, size, hdr2.ShapeType, &res)
+	if err != nil {
+		return res, err
+	}
+
+	res.Records = len(records)
+
+	if end < size {
+		if opts.Truncate {
+			if !opts.DryRun {
+				err = f.Truncate(end)
+				if err != nil {
+					return res, err
+				}
+			}
+
+			res.change(`shp`, end, `truncated %d bytes of partial record`, size-end)
+			size = end
+		} else {
+			res.warn(`%d bytes of partial record at offset %d, not truncated`, size-end, end)
+		}
+	}
+
+	newHdr1, newHdr2 := hdr1, hdr2
+	// Records are whole words, so end is even. Untruncated partial record is left outside of file length.
+	newHdr1.Length = uint32(end / 2)
+	setBounds(&newHdr2, b)
+
+	if newHdr1.Length != hdr1.Length {
+		res.change(`shp`, headerLengthOffset, `file length %d -> %d bytes`, int64(hdr1.Length)*2, int64(newHdr1.Length)*2)
+	}
+
+	if newHdr2.Min != hdr2.Min || newHdr2.Max != hdr2.Max {
+		res.change(`shp`, headerBoxOffset, `box X %v..%v Y %v..%v -> X %v..%v Y %v..%v`,
+			hdr2.Min.X, hdr2.Max.X, hdr2.Min.Y, hdr2.Max.Y, newHdr2.Min.X, newHdr2.Max.X, newHdr2.Min.Y, newHdr2.Max.Y)
+	}
+
+	if newHdr2.Z != hdr2.Z {
+		res.change(`shp`, headerZOffset, `Z range %v..%v -> %v..%v`, hdr2.Z.Min, hdr2.Z.Max, newHdr2.Z.Min, newHdr2.Z.Max)
+	}
+
+	if newHdr2.M != hdr2.M {
+		res.change(`shp`, headerMOffset, `M range %v..%v -> %v..%v`, hdr2.M.Min, hdr2.M.Max, newHdr2.M.Min, newHdr2.M.Max)
+	}
+
+	if !opts.DryRun && (newHdr1 != hdr1 || newHdr2 != hdr2) {
+		_, err = f.WriteAt(encodeHeader(newHdr1, newHdr2), 0)
+		if err != nil {
+			return res, err
+		}
+	}
+
+	err = rebuildShx(shxPath(fpath), newHdr1, newHdr2, records, opts, &res)
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+// Read headers without validating file length, which is often the broken part
+func readHeader(r io.Reader) (hdr1 common.ShapeFileHeader1, hdr2 common.ShapeFileHeader2, err error) {
+	err = binary.Read(r, binary.BigEndian, &hdr1)
+	if err != nil {
+		return hdr1, hdr2, err
+	}
+
+	err = binary.Read(r, binary.LittleEndian, &hdr2)
+	if err != nil {
+		return hdr1, hdr2, err
+	}
+
+	if hdr1.FileCode != common.HeaderFileCode {
+		return hdr1, hdr2, &common.InvalidFileCode{Code: hdr1.FileCode}
+	}
+
+	err = hdr2.Validate()
+	if err != nil {
+		return hdr1, hdr2, err
+	}
+
+	return hdr1, hdr2, nil
+}
+
+func encodeHeader(hdr1 common.ShapeFileHeader1, hdr2 common.ShapeFileHeader2) []byte {
+	var buf bytes.Buffer
+	// Writes to bytes.Buffer don't fail
+	_ = binary.Write(&buf, binary.BigEndian, hdr1)
+	_ = binary.Write(&buf, binary.LittleEndian, hdr2)
+	return buf.Bytes()
+}
+
+// Scan record headers to end of file or first invalid record. end is where last valid record ends.
+func scan(f *os.File, size int64, shapeType common.ShapeType, res *Result) (records []record, b bounds, end int64, err error) {
+	end = headerSize
+
+	for end+recordHeaderSize <= size {
+		var hdr [recordHeaderSize]byte
+		_, err = f.ReadAt(hdr[:], end)
+		if err != nil {
+			return nil, b, end, err
+		}
+
+		number := binary.BigEndian.Uint32(hdr[0:])
+		length := common.WordsToBytes(hdr[4:])
+
+		if number == 0 || length < 4 || length > size-end-recordHeaderSize {
+			// Partial record or not a record at all
+			break
+		}
+
+		if number != uint32(len(records)+1) {
+			res.warn(`record at offset %d has number %d, should be %d`, end, number, len(records)+1)
+		}
+
+		content := make([]byte, length)
+		_, err = f.ReadAt(content, end+recordHeaderSize)
+		if err != nil && err != io.EOF {
+			return nil, b, end, err
+		}
+
+		st := common.ShapeType(binary.LittleEndian.Uint32(content))
+		if st != common.NULL && st != shapeType {
+			break
+		}
+
+		shape, err := shp.ParseRecord(content)
+		if err != nil {
+			res.warn(`record #%d at offset %d couldn't be decoded, not used for bounding box: %v`, len(records)+1, end, err)
+		} else {
+			b.addShape(shape)
+		}
+
+		records = append(records, record{offset: end, length: length})
+		end += recordHeaderSize + length
+	}
+
+	return records, b, end, nil
+}
+
+// Z and M ranges are only set for types which have them, others must be 0
+func setBounds(hdr *common.ShapeFileHeader2, b bounds) {
+	if !b.X.ok {
+		// No points, keep header ranges
+		return
+	}
+
+	hdr.Min.X, hdr.Max.X = b.X.Min, b.X.Max
+	hdr.Min.Y, hdr.Max.Y = b.Y.Min, b.Y.Max
+
+	hdr.Z.Min, hdr.Z.Max = 0, 0
+	hdr.M.Min, hdr.M.Max = 0, 0
+
+	if b.Z.ok {
+		hdr.Z.Min, hdr.Z.Max = b.Z.Min, b.Z.Max
+	}
+
+	if b.M.ok {
+		hdr.M.Min, hdr.M.Max = b.M.Min, b.M.Max
+	}
+}
+
+// Existing .shx with any case of extension, or new name matching case of .shp extension
+func shxPath(fpath string) string {
+	ext := filepath.Ext(fpath)
+	base := strings.TrimSuffix(fpath, ext)
+
+	for _, e := range []string{`.shx`, `.SHX`, `.Shx`} {
+		if _, err := os.Stat(base + e); err == nil {
+			return base + e
+		}
+	}
+
+	if ext == strings.ToUpper(ext) {
+		return base + `.SHX`
+	}
+
+	return base + `.shx`
+}
+
+func rebuildShx(fpath string, hdr1 common.ShapeFileHeader1, hdr2 common.ShapeFileHeader2, records []record, opts Options, res *Result) (err error) {
+	hdr1.Length = uint32(headerSize+len(records)*shxEntrySize) / 2
+
+	data := encodeHeader(hdr1, hdr2)
+	for _, rec := range records {
+		var entry [shxEntrySize]byte
+		binary.BigEndian.PutUint32(entry[0:], uint32(rec.offset/2))
+		binary.BigEndian.PutUint32(entry[4:], uint32(rec.length/2))
+		data = append(data, entry[:]...)
+	}
+
+	old, err := ioutil.ReadFile(fpath)
+	switch {
+	case os.IsNotExist(err):
+		res.change(`shx`, -1, `created with %d entries`, len(records))
+	case err != nil:
+		return err
+	case bytes.Equal(old, data):
+		return nil
+	default:
+		res.change(`shx`, -1, `rebuilt with %d entries: %v`, len(records), describeShxDiff(old, data))
+	}
+
+	if opts.DryRun {
+		return nil
+	}
+
+	// Write to temporary file first so that old .shx is kept if writing fails
+	tmp := fpath + `.tmp`
+	err = ioutil.WriteFile(tmp, data, 0644)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, fpath)
+}
+
+// Describe first difference between old and new .shx
+func describeShxDiff(old, data []byte) string {
+	if len(old) < headerSize {
+		return fmt.Sprintf(`old file was only %d bytes`, len(old))
+	}
+
+	oldCount := (len(old) - headerSize) / shxEntrySize
+	newCount := (len(data) - headerSize) / shxEntrySize
+
+	for idx := 0; idx < oldCount && idx < newCount; idx++ {
+		at := headerSize + idx*shxEntrySize
+		o, n := old[at:at+shxEntrySize], data[at:at+shxEntrySize]
+
+		if !bytes.Equal(o, n) {
+			return fmt.Sprintf(`entry #%d was offset %d length %d, is offset %d length %d`, idx+1,
+				int64(binary.BigEndian.Uint32(o[0:]))*2, int64(binary.BigEndian.Uint32(o[4:]))*2,
+				int64(binary.BigEndian.Uint32(n[0:]))*2, int64(binary.BigEndian.Uint32(n[4:]))*2)
+		}
+	}
+
+	if oldCount != newCount || len(old) != len(data) {
+		return fmt.Sprintf(`old file had %d entries`, oldCount)
+	}
+
+	return `header updated`
+}
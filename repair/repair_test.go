@@ -0,0 +1,206 @@
+package repair
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/raspi/GeoESRIShapeFile/common"
+	"github.com/raspi/GeoESRIShapeFile/validate"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Copy polyline.shp without .shx, with zero header length and partial record at end
+func brokenCopy(t *testing.T) (dir, fpath string, orig []byte) {
+	dir, err := ioutil.TempDir(``, `repair`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	orig, err = ioutil.ReadFile(filepath.Join(`..`, `_test_files`, `polyline.shp`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := append([]byte{}, orig...)
+	binary.BigEndian.PutUint32(b[headerLengthOffset:], 0)
+	b = append(b, 0, 0, 0, 3, 0, 0, 1, 0, 1, 2)
+
+	fpath = filepath.Join(dir, `broken.shp`)
+	err = ioutil.WriteFile(fpath, b, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return dir, fpath, orig
+}
+
+func TestRepair(t *testing.T) {
+	dir, fpath, orig := brokenCopy(t)
+	defer os.RemoveAll(dir)
+
+	res, err := Dataset(fpath, Options{Truncate: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Records != 2 {
+		t.Fatalf(`expected 2 records, got %d`, res.Records)
+	}
+
+	if len(res.Changes) != 3 {
+		t.Fatalf(`expected truncate, file length and .shx changes, got %v`, res.Changes)
+	}
+
+	b, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(b, orig) {
+		t.Fatalf(`repaired .shp differs from original`)
+	}
+
+	shx, err := ioutil.ReadFile(filepath.Join(dir, `broken.shx`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	orig, err = ioutil.ReadFile(filepath.Join(`..`, `_test_files`, `polyline.shx`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(shx, orig) {
+		t.Fatalf(`rebuilt .shx differs from original`)
+	}
+
+	r, err := validate.Dataset(fpath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, f := range r.Findings {
+		if f.File != `dbf` {
+			t.Errorf(`unexpected finding after repair: %v`, f)
+		}
+	}
+}
+
+func TestRepairDryRun(t *testing.T) {
+	dir, fpath, _ := brokenCopy(t)
+	defer os.RemoveAll(dir)
+
+	before, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := Dataset(fpath, Options{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res.Changes) != 2 || len(res.Warnings) != 1 {
+		t.Fatalf(`expected file length and .shx changes and partial record warning, got %v %v`, res.Changes, res.Warnings)
+	}
+
+	after, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(before, after) {
+		t.Fatalf(`dry run modified .shp`)
+	}
+
+	if _, err = os.Stat(filepath.Join(dir, `broken.shx`)); !os.IsNotExist(err) {
+		t.Fatalf(`dry run created .shx`)
+	}
+}
+
+// Content length of 2^31 words or more must not wrap around to a small length
+func TestRepairHugeRecordLength(t *testing.T) {
+	dir, fpath, orig := brokenCopy(t)
+	defer os.RemoveAll(dir)
+
+	b, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Partial record has 2 bytes of content, 0x80000001 words would wrap to that
+	binary.BigEndian.PutUint32(b[len(orig)+4:], 0x80000001)
+
+	err = ioutil.WriteFile(fpath, b, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := Dataset(fpath, Options{Truncate: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Records != 2 {
+		t.Fatalf(`expected 2 records, got %d`, res.Records)
+	}
+
+	b, err = ioutil.ReadFile(fpath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(b, orig) {
+		t.Fatalf(`record past end of file wasn't truncated`)
+	}
+}
+
+// Zero padding after last record is not a run of empty records
+func TestRepairZeroPadding(t *testing.T) {
+	dir, fpath, orig := brokenCopy(t)
+	defer os.RemoveAll(dir)
+
+	b := append([]byte{}, orig...)
+	b = append(b, make([]byte, 64)...)
+
+	err := ioutil.WriteFile(fpath, b, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := Dataset(fpath, Options{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Records != 2 {
+		t.Fatalf(`expected 2 records, got %d`, res.Records)
+	}
+
+	if len(res.Warnings) != 1 {
+		t.Fatalf(`expected partial record warning, got %v`, res.Warnings)
+	}
+
+	for _, c := range res.Changes {
+		if c.File == `shp` {
+			t.Errorf(`header length should match last record, got change %v`, c)
+		}
+	}
+}
+
+// Z and M ranges are kept like box when there are no points
+func TestSetBoundsNoPoints(t *testing.T) {
+	var hdr common.ShapeFileHeader2
+	hdr.Min.X, hdr.Max.X = 1, 2
+	hdr.Z.Min, hdr.Z.Max = 3, 4
+	hdr.M.Min, hdr.M.Max = 5, 6
+
+	want := hdr
+	setBounds(&hdr, bounds{})
+
+	if hdr != want {
+		t.Fatalf(`header changed to %v, should be %v`, hdr, want)
+	}
+}
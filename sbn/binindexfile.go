@@ -0,0 +1,101 @@
+package sbn
+
+import (
+	"fmt"
+	"github.com/raspi/GeoESRIShapeFile/common"
+	"io"
+)
+
+// Offsets for bins in .sbn file
+type BinIndexRecord struct {
+	Offset int64 // bin offset in bytes
+	Length int64 // bin length in bytes
+}
+
+func (bi BinIndexRecord) String() string {
+	return fmt.Sprintf(`offset 0x%04[1]x (%06[1]d) with len 0x%04[2]x (%06[2]d)`, bi.Offset, bi.Length)
+}
+
+// BinIndexFile is a read-only parser for ESRI .sbx which holds offsets of bins in .sbn
+type BinIndexFile struct {
+	Header Header
+
+	r           common.ReadSeekCloser
+	logger      common.Logger // nil for no logging
+	initialized bool
+}
+
+func NewIndex(fname string) (bi BinIndexFile, err error) {
+	f, err := common.OpenFile(fname)
+	if err != nil {
+		return bi, err
+	}
+
+	return NewIndexFrom(f), nil
+}
+
+// Read .sbx from already opened file, for example from afero.Fs or fs.FS. Close closes r.
+func NewIndexFrom(r common.ReadSeekCloser) BinIndexFile {
+	return BinIndexFile{
+		r:           r,
+		initialized: false,
+	}
+}
+
+// Set logger, for example *slog.Logger. nil disables logging, which is the default.
+func (bi *BinIndexFile) SetLogger(l common.Logger) {
+	bi.logger = l
+}
+
+func (bi *BinIndexFile) GetLogger() common.Logger {
+	return bi.logger
+}
+
+// Deprecated: use SetLogger. true logs debug messages with standard log package.
+func (bi *BinIndexFile) SetDebug(flag bool) {
+	bi.logger = common.DebugLogger(flag)
+}
+
+func (bi *BinIndexFile) GetDebug() bool {
+	return bi.logger != nil
+}
+
+func (bi *BinIndexFile) Close() error {
+	if bi == nil || bi.r == nil {
+		// Not opened
+		return nil
+	}
+
+	return bi.r.Close()
+}
+
+func (bi *BinIndexFile) Initialize() (err error) {
+	bi.Header, err = readHeader(bi.r)
+	if err != nil {
+		return err
+	}
+
+	if bi.logger != nil {
+		bi.logger.Debug(`header read successfully`)
+	}
+
+	bi.initialized = true
+	return nil
+}
+
+func (bi *BinIndexFile) ReadRecord() (o BinIndexRecord, err error) {
+	if !bi.initialized {
+		return o, common.ErrorNotInitialized
+	}
+
+	var entry [8]byte
+	_, err = io.ReadFull(bi.r, entry[:])
+	if err != nil {
+		return o, err
+	}
+
+	o.Offset = common.WordsToBytes(entry[0:])
+	o.Length = common.WordsToBytes(entry[4:])
+
+	return o, nil
+}
@@ -0,0 +1,56 @@
+package sbn
+
+import (
+	"fmt"
+)
+
+type InvalidFileCode struct {
+	Code int32
+}
+
+func (e *InvalidFileCode) Error() string {
+	return fmt.Sprintf(`invalid file code: %d`, e.Code)
+}
+
+type InvalidMagic struct {
+	Magic int32
+}
+
+func (e *InvalidMagic) Error() string {
+	return fmt.Sprintf(`invalid magic: %d`, e.Magic)
+}
+
+type InvalidHeaderLength struct {
+	Value int32
+}
+
+func (e *InvalidHeaderLength) Error() string {
+	return fmt.Sprintf(`invalid length: %d`, e.Value)
+}
+
+type InvalidShapeCount struct {
+	Count int32
+}
+
+func (e *InvalidShapeCount) Error() string {
+	return fmt.Sprintf(`invalid shape count: %d`, e.Count)
+}
+
+type InvalidNodeCount struct {
+	Count int
+	Max   int
+}
+
+func (e *InvalidNodeCount) Error() string {
+	return fmt.Sprintf(`invalid node count: %d (max %d)`, e.Count, e.Max)
+}
+
+type InvalidBin struct {
+	ID     int32
+	Length int32
+	Offset int64
+}
+
+func (e *InvalidBin) Error() string {
+	return fmt.Sprintf(`invalid bin #%d with len %d at offset 0x%04[3]x (%06[3]d)`, e.ID, e.Length, e.Offset)
+}
@@ -0,0 +1,95 @@
+package sbn
+
+import (
+	"fmt"
+)
+
+const (
+	HeaderFileCode = 9994
+	HeaderMagic    = -400 // Bytes 4-7, 0xFFFFFE70
+	maxShapeCount  = 256000000
+	maxDepth       = 15
+)
+
+/*
+Header shared by .sbn and .sbx. Format is not documented by ESRI, this follows the
+reverse-engineered description used by shapelib.
+
+Byte 0  File Code    9994        Integer Big
+Byte 4  Magic        -400        Integer Big
+Byte 8  Unused       0           Integer Big
+Byte 12 Unused       0           Integer Big
+Byte 16 Unused       0           Integer Big
+Byte 20 Unused       0           Integer Big
+Byte 24 File Length  File Length Integer Big
+Byte 28 Shape Count  Shape Count Integer Big
+*/
+type rawHeader1 struct {
+	FileCode   int32
+	Magic      int32
+	Unused     [4]int32
+	Length     int32
+	ShapeCount int32
+}
+
+/*
+Byte 32  Bounding Box Xmin Double Little
+Byte 40  Bounding Box Ymin Double Little
+Byte 48  Bounding Box Xmax Double Little
+Byte 56  Bounding Box Ymax Double Little
+Byte 64  Bounding Box Zmin Double Little
+Byte 72  Bounding Box Zmax Double Little
+Byte 80  Bounding Box Mmin Double Little
+Byte 88  Bounding Box Mmax Double Little
+Byte 96  Unused           Integer
+*/
+type rawHeader2 struct {
+	MinX, MinY, MaxX, MaxY float64
+	MinZ, MaxZ             float64
+	MinM, MaxM             float64
+	_                      int32
+}
+
+// Proper header for this library
+type Header struct {
+	Length     int64 // File length in bytes
+	ShapeCount int   // How many shapes are indexed
+
+	MinX, MinY, MaxX, MaxY float64
+	MinZ, MaxZ             float64
+	MinM, MaxM             float64
+}
+
+func (h Header) String() string {
+	return fmt.Sprintf(`len:%d shapes:%d min:%f, %f max:%f, %f`, h.Length, h.ShapeCount, h.MinX, h.MinY, h.MaxX, h.MaxY)
+}
+
+func (h rawHeader1) Validate() error {
+	if h.FileCode != HeaderFileCode {
+		return &InvalidFileCode{Code: h.FileCode}
+	}
+
+	if h.Magic != HeaderMagic {
+		return &InvalidMagic{Magic: h.Magic}
+	}
+
+	if h.Length <= 0 {
+		return &InvalidHeaderLength{Value: h.Length}
+	}
+
+	if h.ShapeCount < 0 || h.ShapeCount > maxShapeCount {
+		return &InvalidShapeCount{Count: h.ShapeCount}
+	}
+
+	return nil
+}
+
+// Tree depth is not stored in the file, it is derived from the shape count
+func treeDepth(shapeCount int) (depth int) {
+	depth = 2
+	for depth < maxDepth && shapeCount > ((1<<uint(depth))-1)*8 {
+		depth++
+	}
+
+	return depth
+}
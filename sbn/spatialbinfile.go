@@ -0,0 +1,406 @@
+package sbn
+
+import (
+	"encoding/binary"
+	"fmt"
+	"github.com/raspi/GeoESRIShapeFile/common"
+	"github.com/raspi/GeoESRIShapeFile/shp"
+	"golang.org/x/xerrors"
+	"io"
+	"math"
+	"sort"
+)
+
+const (
+	maxFeaturesPerBin = 100
+	featureSize       = 8
+)
+
+// Bin header, precedes node descriptors and every bin
+type rawBinHeader struct {
+	ID     int32
+	Length int32 // In 16-bit words
+}
+
+// Node descriptor, one per tree node
+type rawNodeDescriptor struct {
+	BinStart   int32 // First bin of this node, 0 if empty
+	ShapeCount int32
+}
+
+// Single feature in a bin. Bounding box is stored as bytes (0-255) relative to header bounding box
+type rawFeature struct {
+	MinX, MinY, MaxX, MaxY uint8
+	ID                     int32 // 1-based record number in .shp
+}
+
+// Feature in spatial bin index
+type Feature struct {
+	ID  uint32   // 0-based record number, same as returned by shp.ShapeFile.ReadRecord
+	Box shp.Box  // Bounding box in header coordinates, rounded outwards
+	Raw [4]uint8 // Bounding box in bin coordinates: min x, min y, max x, max y
+}
+
+func (f Feature) String() string {
+	return fmt.Sprintf(`#%d Box(%v)`, f.ID, f.Box)
+}
+
+// Bin holds up to 100 features of a single node
+type Bin struct {
+	ID       int32
+	Offset   int64   // Offset of bin header in .sbn
+	Box      shp.Box // Bounding box covering all features in this bin
+	Features []Feature
+	raw      [4]uint8
+}
+
+func (b Bin) String() string {
+	return fmt.Sprintf(`bin #%d at offset 0x%04[2]x (%06[2]d) with %d features Box(%v)`, b.ID, b.Offset, len(b.Features), b.Box)
+}
+
+// Node in spatial tree
+type Node struct {
+	ID       int   // 1-based node number
+	BinStart int32 // Bin number from node descriptor
+	Count    int   // How many features
+	Bins     []int // Indexes to SpatialBinFile.Bins
+}
+
+// SpatialBinFile is a read-only parser for ESRI .sbn spatial bin index
+type SpatialBinFile struct {
+	Header Header
+	Depth  int // Tree depth derived from shape count
+	Nodes  []Node
+	Bins   []Bin
+
+	r           common.ReadSeekCloser
+	logger      common.Logger // nil for no logging
+	initialized bool
+}
+
+func New(fname string) (sb SpatialBinFile, err error) {
+	f, err := common.OpenFile(fname)
+	if err != nil {
+		return sb, err
+	}
+
+	return NewFrom(f), nil
+}
+
+// Read from already opened file, for example from afero.Fs or fs.FS. Close closes r.
+func NewFrom(r common.ReadSeekCloser) SpatialBinFile {
+	return SpatialBinFile{
+		r:           r,
+		initialized: false,
+	}
+}
+
+// Set logger, for example *slog.Logger. nil disables logging, which is the default.
+func (sb *SpatialBinFile) SetLogger(l common.Logger) {
+	sb.logger = l
+}
+
+func (sb *SpatialBinFile) GetLogger() common.Logger {
+	return sb.logger
+}
+
+// Deprecated: use SetLogger. true logs debug messages with standard log package.
+func (sb *SpatialBinFile) SetDebug(flag bool) {
+	sb.logger = common.DebugLogger(flag)
+}
+
+func (sb *SpatialBinFile) GetDebug() bool {
+	return sb.logger != nil
+}
+
+func (sb *SpatialBinFile) Close() error {
+	if sb == nil || sb.r == nil {
+		// Not opened
+		return nil
+	}
+
+	return sb.r.Close()
+}
+
+// Read whole index to memory. Bins are small (8 bytes per feature) so this is cheap compared to .shp
+func (sb *SpatialBinFile) Initialize() (err error) {
+	sb.Header, err = readHeader(sb.r)
+	if err != nil {
+		return xerrors.Errorf(`error reading header: %w`, err)
+	}
+
+	if sb.Header.ShapeCount == 0 {
+		// Empty index
+		sb.initialized = true
+		return nil
+	}
+
+	sb.Depth = treeDepth(sb.Header.ShapeCount)
+
+	err = sb.readNodes()
+	if err != nil {
+		return xerrors.Errorf(`error reading node descriptors: %w`, err)
+	}
+
+	err = sb.readBins()
+	if err != nil {
+		return xerrors.Errorf(`error reading bins: %w`, err)
+	}
+
+	if sb.logger != nil {
+		sb.logger.Debug(`index read successfully`, `nodes`, len(sb.Nodes), `bins`, len(sb.Bins))
+	}
+
+	sb.initialized = true
+	return nil
+}
+
+// Read .sbn/.sbx header (notice endianness!)
+func readHeader(r io.Reader) (hdr Header, err error) {
+	var hdr1 rawHeader1
+	err = binary.Read(r, binary.BigEndian, &hdr1)
+	if err != nil {
+		return hdr, err
+	}
+
+	err = hdr1.Validate()
+	if err != nil {
+		return hdr, err
+	}
+
+	var hdr2 rawHeader2
+	err = binary.Read(r, binary.LittleEndian, &hdr2)
+	if err != nil {
+		return hdr, err
+	}
+
+	return Header{
+		Length:     int64(hdr1.Length) * 2,
+		ShapeCount: int(hdr1.ShapeCount),
+		MinX:       hdr2.MinX,
+		MinY:       hdr2.MinY,
+		MaxX:       hdr2.MaxX,
+		MaxY:       hdr2.MaxY,
+		MinZ:       hdr2.MinZ,
+		MaxZ:       hdr2.MaxZ,
+		MinM:       hdr2.MinM,
+		MaxM:       hdr2.MaxM,
+	}, nil
+}
+
+func (sb *SpatialBinFile) readNodes() (err error) {
+	var binhdr rawBinHeader
+	err = binary.Read(sb.r, binary.BigEndian, &binhdr)
+	if err != nil {
+		return err
+	}
+
+	maxNodes := (1 << uint(sb.Depth)) - 1
+	nodeCount := int(binhdr.Length) * 2 / binary.Size(rawNodeDescriptor{})
+
+	if binhdr.Length < 0 || nodeCount > maxNodes {
+		return &InvalidNodeCount{Count: nodeCount, Max: maxNodes}
+	}
+
+	rawnodes := make([]rawNodeDescriptor, nodeCount)
+	err = binary.Read(sb.r, binary.BigEndian, &rawnodes)
+	if err != nil {
+		return err
+	}
+
+	sb.Nodes = make([]Node, 0, nodeCount)
+	for idx, n := range rawnodes {
+		if n.ShapeCount < 0 || int(n.ShapeCount) > sb.Header.ShapeCount {
+			return fmt.Errorf(`node #%d has invalid shape count %d`, idx+1, n.ShapeCount)
+		}
+
+		sb.Nodes = append(sb.Nodes, Node{
+			ID:       idx + 1,
+			BinStart: n.BinStart,
+			Count:    int(n.ShapeCount),
+		})
+	}
+
+	return nil
+}
+
+// Bins are stored in node order and features of a node never share a bin with other nodes
+func (sb *SpatialBinFile) readBins() (err error) {
+	for nidx := range sb.Nodes {
+		node := &sb.Nodes[nidx]
+
+		remaining := node.Count
+		for remaining > 0 {
+			bin, err := sb.readBin()
+			if err != nil {
+				return xerrors.Errorf(`node #%d: %w`, node.ID, err)
+			}
+
+			if len(bin.Features) > remaining {
+				return fmt.Errorf(`node #%d has %d features but bin #%d has %d`, node.ID, remaining, bin.ID, len(bin.Features))
+			}
+
+			remaining -= len(bin.Features)
+			node.Bins = append(node.Bins, len(sb.Bins))
+			sb.Bins = append(sb.Bins, bin)
+		}
+	}
+
+	return nil
+}
+
+func (sb *SpatialBinFile) readBin() (bin Bin, err error) {
+	bin.Offset, err = sb.r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return bin, err
+	}
+
+	var binhdr rawBinHeader
+	err = binary.Read(sb.r, binary.BigEndian, &binhdr)
+	if err != nil {
+		return bin, err
+	}
+
+	bin.ID = binhdr.ID
+	count := int(binhdr.Length) * 2 / featureSize
+
+	if binhdr.Length <= 0 || count > maxFeaturesPerBin || int(binhdr.Length)*2%featureSize != 0 {
+		return bin, &InvalidBin{ID: binhdr.ID, Length: binhdr.Length, Offset: bin.Offset}
+	}
+
+	rawfeatures := make([]rawFeature, count)
+	err = binary.Read(sb.r, binary.BigEndian, &rawfeatures)
+	if err != nil {
+		return bin, err
+	}
+
+	bin.raw = [4]uint8{math.MaxUint8, math.MaxUint8, 0, 0}
+	bin.Features = make([]Feature, 0, count)
+
+	for _, rf := range rawfeatures {
+		if rf.ID <= 0 || int(rf.ID) > sb.Header.ShapeCount {
+			return bin, fmt.Errorf(`bin #%d has invalid feature id %d`, bin.ID, rf.ID)
+		}
+
+		f := Feature{
+			ID:  uint32(rf.ID - 1),
+			Raw: [4]uint8{rf.MinX, rf.MinY, rf.MaxX, rf.MaxY},
+		}
+		f.Box = sb.toBox(f.Raw)
+
+		bin.raw = union(bin.raw, f.Raw)
+		bin.Features = append(bin.Features, f)
+	}
+
+	bin.Box = sb.toBox(bin.raw)
+
+	if sb.logger != nil {
+		sb.logger.Debug(`read bin`, `bin`, bin)
+	}
+
+	return bin, nil
+}
+
+// Convert bin coordinates to header coordinates
+func (sb *SpatialBinFile) toBox(raw [4]uint8) shp.Box {
+	w := (sb.Header.MaxX - sb.Header.MinX) / math.MaxUint8
+	h := (sb.Header.MaxY - sb.Header.MinY) / math.MaxUint8
+
+	return shp.Box{
+		MinX: sb.Header.MinX + float64(raw[0])*w,
+		MinY: sb.Header.MinY + float64(raw[1])*h,
+		MaxX: sb.Header.MinX + float64(raw[2])*w,
+		MaxY: sb.Header.MinY + float64(raw[3])*h,
+	}
+}
+
+// Convert header coordinates to bin coordinates, rounded outwards
+func (sb *SpatialBinFile) toRaw(b shp.Box) (raw [4]uint8, ok bool) {
+	if b.MaxX < sb.Header.MinX || b.MinX > sb.Header.MaxX || b.MaxY < sb.Header.MinY || b.MinY > sb.Header.MaxY {
+		return raw, false
+	}
+
+	raw[0] = scale(b.MinX, sb.Header.MinX, sb.Header.MaxX, math.Floor)
+	raw[1] = scale(b.MinY, sb.Header.MinY, sb.Header.MaxY, math.Floor)
+	raw[2] = scale(b.MaxX, sb.Header.MinX, sb.Header.MaxX, math.Ceil)
+	raw[3] = scale(b.MaxY, sb.Header.MinY, sb.Header.MaxY, math.Ceil)
+
+	return raw, true
+}
+
+func scale(v, min, max float64, round func(float64) float64) uint8 {
+	if max <= min {
+		// Degenerate extent, everything is in the same bin coordinate
+		return 0
+	}
+
+	s := round((v - min) / (max - min) * math.MaxUint8)
+
+	if s < 0 {
+		return 0
+	}
+
+	if s > math.MaxUint8 {
+		return math.MaxUint8
+	}
+
+	return uint8(s)
+}
+
+func union(a, b [4]uint8) [4]uint8 {
+	if b[0] < a[0] {
+		a[0] = b[0]
+	}
+
+	if b[1] < a[1] {
+		a[1] = b[1]
+	}
+
+	if b[2] > a[2] {
+		a[2] = b[2]
+	}
+
+	if b[3] > a[3] {
+		a[3] = b[3]
+	}
+
+	return a
+}
+
+func intersects(a, b [4]uint8) bool {
+	return a[0] <= b[2] && a[2] >= b[0] && a[1] <= b[3] && a[3] >= b[1]
+}
+
+// Search returns sorted 0-based record numbers whose indexed bounding box intersects given box.
+// Boxes in index are rounded outwards so caller should still check actual geometry.
+func (sb *SpatialBinFile) Search(b shp.Box) (ids []uint32, err error) {
+	if !sb.initialized {
+		return nil, common.ErrorNotInitialized
+	}
+
+	q, ok := sb.toRaw(b)
+	if !ok {
+		return nil, nil
+	}
+
+	seen := make(map[uint32]bool)
+
+	for _, bin := range sb.Bins {
+		if !intersects(bin.raw, q) {
+			continue
+		}
+
+		for _, f := range bin.Features {
+			if !intersects(f.Raw, q) || seen[f.ID] {
+				continue
+			}
+
+			seen[f.ID] = true
+			ids = append(ids, f.ID)
+		}
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	return ids, nil
+}
@@ -0,0 +1,123 @@
+package sbn
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/raspi/GeoESRIShapeFile/common"
+	"github.com/raspi/GeoESRIShapeFile/shp"
+	"reflect"
+	"testing"
+)
+
+func TestHeaderSize(t *testing.T) {
+	actual := binary.Size(rawHeader1{}) + binary.Size(rawHeader2{})
+	if actual != 100 {
+		t.Fatalf(`header size was %v, should be 100`, actual)
+	}
+}
+
+func TestFeatureSize(t *testing.T) {
+	actual := binary.Size(rawFeature{})
+	if actual != featureSize {
+		t.Fatalf(`feature size was %v, should be %v`, actual, featureSize)
+	}
+}
+
+// Three shapes in 0-255 x 0-255 extent, two in root node and one in third node
+func testSpatialBinData(t *testing.T) []byte {
+	var buf bytes.Buffer
+
+	w := func(order binary.ByteOrder, data interface{}) {
+		err := binary.Write(&buf, order, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w(binary.BigEndian, rawHeader1{FileCode: HeaderFileCode, Magic: HeaderMagic, Length: 100, ShapeCount: 3})
+	w(binary.LittleEndian, rawHeader2{MaxX: 255, MaxY: 255})
+
+	w(binary.BigEndian, rawBinHeader{ID: 0, Length: 12})
+	w(binary.BigEndian, []rawNodeDescriptor{{BinStart: 1, ShapeCount: 2}, {}, {BinStart: 2, ShapeCount: 1}})
+
+	w(binary.BigEndian, rawBinHeader{ID: 1, Length: 8})
+	w(binary.BigEndian, []rawFeature{{MinX: 0, MinY: 0, MaxX: 10, MaxY: 10, ID: 1}, {MinX: 100, MinY: 100, MaxX: 200, MaxY: 200, ID: 3}})
+
+	w(binary.BigEndian, rawBinHeader{ID: 2, Length: 4})
+	w(binary.BigEndian, []rawFeature{{MinX: 50, MinY: 50, MaxX: 60, MaxY: 60, ID: 2}})
+
+	return buf.Bytes()
+}
+
+func TestSpatialBinFileSearch(t *testing.T) {
+	r, err := common.NewReadSeekCloser(testSpatialBinData(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sb := SpatialBinFile{r: r}
+	err = sb.Initialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sb.Nodes) != 3 || len(sb.Bins) != 2 {
+		t.Fatalf(`got %d nodes and %d bins, should be 3 and 2`, len(sb.Nodes), len(sb.Bins))
+	}
+
+	expectedBox := shp.Box{MinX: 0, MinY: 0, MaxX: 200, MaxY: 200}
+	if sb.Bins[0].Box != expectedBox {
+		t.Fatalf(`bin box was %v, should be %v`, sb.Bins[0].Box, expectedBox)
+	}
+
+	tests := []struct {
+		box      shp.Box
+		expected []uint32
+	}{
+		{shp.Box{MinX: 5, MinY: 5, MaxX: 55, MaxY: 55}, []uint32{0, 1}},
+		{shp.Box{MinX: 150, MinY: 150, MaxX: 151, MaxY: 151}, []uint32{2}},
+		{shp.Box{MinX: 20, MinY: 20, MaxX: 30, MaxY: 30}, nil},
+		{shp.Box{MinX: -10, MinY: -10, MaxX: 300, MaxY: 300}, []uint32{0, 1, 2}},
+		{shp.Box{MinX: 300, MinY: 300, MaxX: 400, MaxY: 400}, nil},
+	}
+
+	for _, tt := range tests {
+		actual, err := sb.Search(tt.box)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(actual, tt.expected) {
+			t.Fatalf(`search %v returned %v, should be %v`, tt.box, actual, tt.expected)
+		}
+	}
+}
+
+// Offsets of 2^31 words or more must not wrap around
+func TestBinIndexFileReadRecord(t *testing.T) {
+	var buf bytes.Buffer
+
+	_ = binary.Write(&buf, binary.BigEndian, rawHeader1{FileCode: HeaderFileCode, Magic: HeaderMagic, Length: 54, ShapeCount: 1})
+	_ = binary.Write(&buf, binary.LittleEndian, rawHeader2{MaxX: 255, MaxY: 255})
+	_ = binary.Write(&buf, binary.BigEndian, []uint32{0x80000000, 0x10})
+
+	r, err := common.NewReadSeekCloser(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bi := NewIndexFrom(r)
+	err = bi.Initialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	o, err := bi.ReadRecord()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if o.Offset != 0x100000000 || o.Length != 0x20 {
+		t.Fatalf(`read %v, should be offset 0x100000000 with length 0x20`, o)
+	}
+}
@@ -0,0 +1,52 @@
+package shp
+
+import "sync"
+
+// Buffers larger than this are not returned to pool, so that one huge record doesn't stay in memory
+const maxPooledBuffer = 1024 * 1024
+
+// Record content buffers shared by all readers. Decoders copy everything they keep, so buffer can
+// be returned as soon as record is decoded.
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		return new([]byte)
+	},
+}
+
+// Get buffer of n bytes from pool, return it with putBuffer
+func getBuffer(n int) *[]byte {
+	b := bufferPool.Get().(*[]byte)
+
+	if cap(*b) < n {
+		*b = make([]byte, n)
+	}
+
+	*b = (*b)[:n]
+
+	return b
+}
+
+func putBuffer(b *[]byte) {
+	if cap(*b) > maxPooledBuffer {
+		return
+	}
+
+	bufferPool.Put(b)
+}
+
+// Resize s to n items, reusing its backing array if it's large enough. Contents are not kept.
+func resize[T any](s []T, n int) []T {
+	if cap(s) < n {
+		return make([]T, n)
+	}
+
+	return s[:n]
+}
+
+// Copy of s which doesn't share backing array
+func clone[T any](s []T) []T {
+	c := make([]T, len(s))
+	copy(c, s)
+
+	return c
+}
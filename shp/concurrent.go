@@ -0,0 +1,243 @@
+package shp
+
+import (
+	"encoding/binary"
+	"github.com/raspi/GeoESRIShapeFile/common"
+	"io"
+	"runtime"
+	"sync"
+)
+
+/*
+ConcurrentReader reads records with ReadAt only, so it can be used from several goroutines at the
+same time. See Decode for decoding whole file with several workers.
+*/
+type ConcurrentReader struct {
+	Header1   common.ShapeFileHeader1
+	Header2   common.ShapeFileHeader2
+	r         io.ReaderAt
+	size      int64
+	maxRecord uint32
+}
+
+// Read headers from r of given size, for example *os.File
+func NewConcurrentReader(r io.ReaderAt, size int64) (cr *ConcurrentReader, err error) {
+	cr = &ConcurrentReader{r: r, size: size, maxRecord: DefaultMaxRecordSize}
+
+	cr.Header1, cr.Header2, err = common.ParseHeaders(common.NewReaderAt(r, size))
+	if err != nil {
+		return nil, err
+	}
+
+	return cr, nil
+}
+
+// Set maximum record content length in bytes, see ShapeFile SetMaxRecordSize. Not safe to call while reading.
+func (cr *ConcurrentReader) SetMaxRecordSize(max uint32) {
+	if max == 0 {
+		max = DefaultMaxRecordSize
+	}
+
+	cr.maxRecord = max
+}
+
+// Read record header at offset. Returns io.EOF at end of file.
+func (cr *ConcurrentReader) ReadRecordHeaderAt(offset int64) (hdr RecordHeader, err error) {
+	if offset == cr.size {
+		return hdr, io.EOF
+	}
+
+	var buf [8]byte
+	n, err := cr.r.ReadAt(buf[:], offset)
+	if n != len(buf) {
+		if err == nil || err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+
+		return hdr, err
+	}
+
+	hdr.Number = binary.BigEndian.Uint32(buf[0:])
+	length := common.WordsToBytes(buf[4:])
+
+	if length > int64(cr.maxRecord) {
+		return hdr, &RecordTooLarge{Number: hdr.Number, Length: length, Max: cr.maxRecord}
+	}
+
+	if remaining := cr.size - offset - 8; length > remaining {
+		return hdr, &RecordPastEOF{Number: hdr.Number, Length: length, Remaining: remaining}
+	}
+
+	// Length is at most maximum record size, so it fits
+	hdr.Length = uint32(length)
+
+	return hdr, nil
+}
+
+// Read and decode record at offset. next is offset of next record.
+func (cr *ConcurrentReader) ReadRecordAt(offset int64) (idx uint32, record ShapeTypeI, next int64, err error) {
+	hdr, err := cr.ReadRecordHeaderAt(offset)
+	if err != nil {
+		return 0, nil, 0, err
+	}
+
+	record, err = cr.readContent(offset, hdr.Length)
+	if err != nil {
+		return 0, nil, 0, err
+	}
+
+	return hdr.Number - 1, record, offset + 8 + int64(hdr.Length), nil
+}
+
+// Read and decode content of record at offset
+func (cr *ConcurrentReader) readContent(offset int64, length uint32) (ShapeTypeI, error) {
+	buf := getBuffer(int(length))
+	defer putBuffer(buf)
+	content := *buf
+
+	n, err := cr.r.ReadAt(content, offset+8)
+	if n != len(content) {
+		if err == nil || err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+
+		return nil, err
+	}
+
+	return DecodeRecord(content)
+}
+
+type decodeJob struct {
+	seq    int
+	offset int64
+	hdr    RecordHeader
+	err    error // Reading header failed, passed through to output
+}
+
+type decodeResult struct {
+	seq    int
+	number uint32
+	record ShapeTypeI
+	err    error
+}
+
+/*
+Decode all records with given number of workers, 0 uses runtime.GOMAXPROCS. fn is called from the
+calling goroutine in record order. Decoding stops at first error, which is returned as *RecordError,
+or when fn returns an error, which is returned as-is.
+*/
+func (cr *ConcurrentReader) Decode(workers int, fn func(idx uint32, record ShapeTypeI) error) (err error) {
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	done := make(chan struct{})
+	jobs := make(chan decodeJob)
+	results := make(chan decodeResult)
+	// Limits how many decoded records can wait for a slow earlier record
+	window := make(chan struct{}, 4*workers)
+
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(jobs)
+		cr.scan(done, window, jobs)
+	}()
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := range jobs {
+				res := decodeResult{seq: j.seq, number: j.hdr.Number - 1, err: j.err}
+				if res.err == nil {
+					res.record, res.err = cr.readContent(j.offset, j.hdr.Length)
+				}
+
+				if res.err != nil {
+					res.err = &RecordError{Number: uint32(j.seq), Offset: j.offset, Err: res.err}
+				}
+
+				select {
+				case results <- res:
+				case <-done:
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	defer func() {
+		// Stop workers and wait for them to finish
+		close(done)
+		for range results {
+		}
+	}()
+
+	pending := make(map[int]decodeResult)
+	next := 0
+
+	for res := range results {
+		pending[res.seq] = res
+
+		for {
+			r, ok := pending[next]
+			if !ok {
+				break
+			}
+
+			delete(pending, next)
+			next++
+			<-window
+
+			if r.err != nil {
+				return r.err
+			}
+
+			err = fn(r.number, r.record)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Read record headers in order and send them as jobs until end of file, error or done is closed
+func (cr *ConcurrentReader) scan(done <-chan struct{}, window chan<- struct{}, jobs chan<- decodeJob) {
+	offset := int64(100)
+
+	for seq := 0; ; seq++ {
+		select {
+		case window <- struct{}{}:
+		case <-done:
+			return
+		}
+
+		hdr, err := cr.ReadRecordHeaderAt(offset)
+		if err == io.EOF {
+			return
+		}
+
+		select {
+		case jobs <- decodeJob{seq: seq, offset: offset, hdr: hdr, err: err}:
+		case <-done:
+			return
+		}
+
+		if err != nil {
+			return
+		}
+
+		offset += 8 + int64(hdr.Length)
+	}
+}
@@ -0,0 +1,294 @@
+package shp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// Write .shp with n polygons of given number of points to temporary directory
+func writePolygons(tb testing.TB, n, points int) string {
+	var buf bytes.Buffer
+
+	contentLength := 44 + 4 + 16*points
+
+	hdr := make([]byte, 100)
+	binary.BigEndian.PutUint32(hdr[0:], 9994)
+	binary.BigEndian.PutUint32(hdr[24:], uint32(100+n*(8+contentLength))/2)
+	binary.LittleEndian.PutUint32(hdr[28:], 1000)
+	binary.LittleEndian.PutUint32(hdr[32:], 5)
+	buf.Write(hdr)
+
+	rec := make([]byte, 8+contentLength)
+	for idx := 0; idx < n; idx++ {
+		binary.BigEndian.PutUint32(rec[0:], uint32(idx+1))
+		binary.BigEndian.PutUint32(rec[4:], uint32(contentLength/2))
+		binary.LittleEndian.PutUint32(rec[8:], 5)
+
+		// Box
+		for i, f := range []float64{0, 0, 1, 1} {
+			binary.LittleEndian.PutUint64(rec[12+8*i:], math.Float64bits(f))
+		}
+
+		binary.LittleEndian.PutUint32(rec[44:], 1)
+		binary.LittleEndian.PutUint32(rec[48:], uint32(points))
+		binary.LittleEndian.PutUint32(rec[52:], 0)
+
+		// Closed clockwise ring around unit circle
+		for i := 0; i < points; i++ {
+			a := -2 * math.Pi * float64(i) / float64(points-1)
+			binary.LittleEndian.PutUint64(rec[56+16*i:], math.Float64bits(0.5+0.5*math.Cos(a)))
+			binary.LittleEndian.PutUint64(rec[64+16*i:], math.Float64bits(0.5+0.5*math.Sin(a)))
+		}
+
+		buf.Write(rec)
+	}
+
+	fpath := filepath.Join(tb.TempDir(), `polygons.shp`)
+	err := ioutil.WriteFile(fpath, buf.Bytes(), 0644)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return fpath
+}
+
+func openConcurrent(t *testing.T, fpath string) *ConcurrentReader {
+	f, err := os.Open(fpath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+
+	fi, err := f.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cr, err := NewConcurrentReader(f, fi.Size())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return cr
+}
+
+func TestConcurrentDecode(t *testing.T) {
+	fpath := writePolygons(t, 500, 20)
+
+	sf, err := New(fpath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sf.Close()
+
+	want := readAllRecords(t, sf)
+
+	for _, workers := range []int{0, 1, 7} {
+		var got []ShapeTypeI
+
+		err = openConcurrent(t, fpath).Decode(workers, func(idx uint32, record ShapeTypeI) error {
+			if int(idx) != len(got) {
+				t.Fatalf(`record #%d out of order, expected #%d`, idx, len(got))
+			}
+
+			got = append(got, record)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf(`%d workers decoded %d records differently`, workers, len(got))
+		}
+	}
+}
+
+func TestConcurrentDecodeStop(t *testing.T) {
+	cr := openConcurrent(t, writePolygons(t, 500, 20))
+	stop := errors.New(`stop`)
+
+	count := 0
+	err := cr.Decode(4, func(idx uint32, record ShapeTypeI) error {
+		count++
+		if count == 10 {
+			return stop
+		}
+
+		return nil
+	})
+
+	if err != stop || count != 10 {
+		t.Fatalf(`expected stop after 10 records, got %v after %d`, err, count)
+	}
+}
+
+func TestConcurrentDecodeError(t *testing.T) {
+	data, err := ioutil.ReadFile(`../_test_files/point.shp`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Invalid shape type in second record
+	binary.LittleEndian.PutUint32(data[128+8:], 99)
+
+	cr, err := NewConcurrentReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	count := 0
+	err = cr.Decode(2, func(idx uint32, record ShapeTypeI) error {
+		count++
+		return nil
+	})
+
+	var recErr *RecordError
+	if !errors.As(err, &recErr) || recErr.Number != 1 || recErr.Offset != 128 || count != 1 {
+		t.Fatalf(`expected error of record #1 after 1 record, got %v after %d`, err, count)
+	}
+
+	_, _, next, err := cr.ReadRecordAt(100)
+	if err != nil || next != 128 {
+		t.Fatalf(`ReadRecordAt returned next %d, %v`, next, err)
+	}
+}
+
+// Content length of 2^31 words or more must not wrap around to a small length
+func TestReadRecordHeaderAtHugeLength(t *testing.T) {
+	data, err := ioutil.ReadFile(`../_test_files/point.shp`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	binary.BigEndian.PutUint32(data[104:], 0x8000000a)
+
+	cr, err := NewConcurrentReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Even largest maximum can't be exceeded by wrapping around
+	cr.SetMaxRecordSize(math.MaxUint32)
+
+	_, err = cr.ReadRecordHeaderAt(100)
+	if tooLarge, ok := err.(*RecordTooLarge); !ok || tooLarge.Length != 0x100000014 {
+		t.Fatalf(`expected RecordTooLarge, got %v`, err)
+	}
+}
+
+const (
+	benchRecords = 2000
+	benchPoints  = 500
+)
+
+func BenchmarkReadRecord(b *testing.B) {
+	fpath := writePolygons(b, benchRecords, benchPoints)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		sf, err := New(fpath)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		err = sf.Initialize()
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		sum := 0.0
+		for {
+			_, shape, err := sf.ReadRecord()
+			if err == io.EOF {
+				break
+			}
+
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			for _, p := range shape.(Polygon).Points {
+				sum += p.X
+			}
+		}
+
+		sf.Close()
+	}
+}
+
+func BenchmarkConcurrentDecode(b *testing.B) {
+	fpath := writePolygons(b, benchRecords, benchPoints)
+
+	f, err := os.Open(fpath)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		cr, err := NewConcurrentReader(f, fi.Size())
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		sum := 0.0
+		err = cr.Decode(0, func(idx uint32, record ShapeTypeI) error {
+			for _, p := range record.(Polygon).Points {
+				sum += p.X
+			}
+
+			return nil
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkReadRecordInto(b *testing.B) {
+	fpath := writePolygons(b, benchRecords, benchPoints)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	var g Geometry
+
+	for i := 0; i < b.N; i++ {
+		sf := openInitialized(b, fpath)
+
+		sum := 0.0
+		for {
+			_, err := sf.ReadRecordInto(&g)
+			if err == io.EOF {
+				break
+			}
+
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			for _, p := range g.Points {
+				sum += p.X
+			}
+		}
+
+		sf.Close()
+	}
+}
@@ -0,0 +1,57 @@
+package shp
+
+/*
+Point in polygon test with even-odd rule over all rings, so holes and multiple outer rings
+are handled without knowing ring orientation. Points exactly on the boundary may be reported
+either inside or outside.
+*/
+func partsContain(parts []uint32, points []Point, pt Point) (inside bool) {
+	for idx := range parts {
+		start := int(parts[idx])
+		end := len(points)
+		if idx+1 < len(parts) {
+			end = int(parts[idx+1])
+		}
+
+		if start >= end || end > len(points) {
+			// Invalid parts, see Validate()
+			continue
+		}
+
+		if RingContains(points[start:end], pt) {
+			inside = !inside
+		}
+	}
+
+	return inside
+}
+
+// Point in single ring test with even-odd rule. Ring may be closed or open.
+func RingContains(ring []Point, pt Point) (inside bool) {
+	j := len(ring) - 1
+
+	for i := 0; i < len(ring); i++ {
+		a, b := ring[i], ring[j]
+
+		if (a.Y > pt.Y) != (b.Y > pt.Y) && pt.X < (b.X-a.X)*(pt.Y-a.Y)/(b.Y-a.Y)+a.X {
+			inside = !inside
+		}
+
+		j = i
+	}
+
+	return inside
+}
+
+// Is point inside polygon and not in a hole
+func (p Polygon) Contains(pt Point) bool {
+	return p.Box.Contains(pt) && partsContain(p.Parts, p.Points, pt)
+}
+
+func (p PolygonM) Contains(pt Point) bool {
+	return p.Box.Contains(pt) && partsContain(p.Parts, p.Points, pt)
+}
+
+func (p PolygonZ) Contains(pt Point) bool {
+	return p.Box.Contains(pt) && partsContain(p.Parts, p.Points, pt)
+}
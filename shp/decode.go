@@ -0,0 +1,144 @@
+package shp
+
+import (
+	"encoding/binary"
+	"fmt"
+	"golang.org/x/xerrors"
+	"io"
+)
+
+// Common start of multi point records: box and point count
+type rawMultiPointHeader struct {
+	Box       Box
+	NumPoints uint32
+}
+
+// Common start of poly line, polygon and multi patch records: box, part count and point count
+type rawPolyHeader struct {
+	Box       Box
+	NumParts  uint32
+	NumPoints uint32
+}
+
+func readMultiPointHeader(r io.Reader) (h rawMultiPointHeader, err error) {
+	err = binary.Read(r, binary.LittleEndian, &h)
+	if err != nil {
+		return h, xerrors.Errorf(`couldn't read raw multi point header: %w`, err)
+	}
+
+	return h, nil
+}
+
+func readPolyHeader(r io.Reader) (h rawPolyHeader, err error) {
+	err = binary.Read(r, binary.LittleEndian, &h)
+	if err != nil {
+		return h, xerrors.Errorf(`couldn't read raw poly header: %w`, err)
+	}
+
+	return h, nil
+}
+
+// Check that count items of given size fit in rest of record content before allocating them.
+// Record content is always read to memory first, so r is a bytes.Reader.
+func checkCount(r io.Reader, field string, count uint32, size int64) error {
+	l, ok := r.(interface{ Len() int })
+	if !ok {
+		return nil
+	}
+
+	need := int64(count) * size
+	if need > int64(l.Len()) {
+		return &InvalidCount{Field: field, Count: count, Need: need, Remaining: int64(l.Len())}
+	}
+
+	return nil
+}
+
+func readParts(r io.Reader, n uint32) (parts []uint32, err error) {
+	err = checkCount(r, `NumParts`, n, 4)
+	if err != nil {
+		return nil, xerrors.Errorf(`parts: %w`, err)
+	}
+
+	parts = make([]uint32, n)
+	err = binary.Read(r, binary.LittleEndian, &parts)
+	if err != nil {
+		return nil, xerrors.Errorf(`parts: %w`, err)
+	}
+
+	return parts, nil
+}
+
+func readPoints(r io.Reader, n uint32) (points []Point, err error) {
+	err = checkCount(r, `NumPoints`, n, 16)
+	if err != nil {
+		return nil, xerrors.Errorf(`points: %w`, err)
+	}
+
+	points = make([]Point, n)
+	err = binary.Read(r, binary.LittleEndian, &points)
+	if err != nil {
+		return nil, xerrors.Errorf(`points: %w`, err)
+	}
+
+	return points, nil
+}
+
+// Read Z or M range and array. Optional range is allowed to be missing completely (M in Z types).
+func readRange(r io.Reader, n uint32, name string, optional bool) (rng [2]float64, arr []float64, err error) {
+	err = binary.Read(r, binary.LittleEndian, &rng)
+	if err == io.EOF && optional {
+		return rng, nil, nil
+	}
+
+	if err != nil {
+		return rng, nil, xerrors.Errorf(`%v range: %w`, name, err)
+	}
+
+	err = checkCount(r, `NumPoints`, n, 8)
+	if err != nil {
+		return rng, nil, xerrors.Errorf(`%v-Array: %w`, name, err)
+	}
+
+	arr = make([]float64, n)
+	err = binary.Read(r, binary.LittleEndian, &arr)
+	if err != nil {
+		return rng, nil, xerrors.Errorf(`%v-Array: %w`, name, err)
+	}
+
+	return rng, arr, nil
+}
+
+// Check counts against decoded slices and that parts are monotonic and point to existing points.
+// First part not starting from 0 is allowed, as it was before parts were checked; validate package
+// reports it.
+func validateParts(numParts, numPoints uint32, parts []uint32, points []Point) error {
+	if len(points) != int(numPoints) {
+		return fmt.Errorf(`numpoints mismatch`)
+	}
+
+	if len(parts) != int(numParts) {
+		return fmt.Errorf(`numparts mismatch`)
+	}
+
+	for idx, p := range parts {
+		if p >= numPoints {
+			return fmt.Errorf(`part #%d index %d out of range`, idx, p)
+		}
+
+		if idx > 0 && p < parts[idx-1] {
+			return fmt.Errorf(`part #%d index %d is not increasing`, idx, p)
+		}
+	}
+
+	return nil
+}
+
+// Check that optional array has either no values or one value per point
+func validateArray(name string, arr []float64, numPoints uint32) error {
+	if arr != nil && len(arr) != int(numPoints) {
+		return fmt.Errorf(`%v-Array length mismatch`, name)
+	}
+
+	return nil
+}
@@ -0,0 +1,164 @@
+package shp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/raspi/GeoESRIShapeFile/common"
+	"golang.org/x/xerrors"
+	"io"
+	"io/ioutil"
+	"math"
+	"testing"
+)
+
+func TestParseRecordInvalidCount(t *testing.T) {
+	// Polygon with one part and 2^32-1 points, but no data for them
+	content := make([]byte, 48)
+	binary.LittleEndian.PutUint32(content[0:], uint32(common.POLYGON))
+	binary.LittleEndian.PutUint32(content[36:], 1)
+	binary.LittleEndian.PutUint32(content[40:], math.MaxUint32)
+
+	_, err := ParseRecord(content)
+
+	var countErr *InvalidCount
+	if !xerrors.As(err, &countErr) {
+		t.Fatalf(`expected InvalidCount, got %v`, err)
+	}
+
+	if countErr.Field != `NumPoints` || countErr.Remaining != 0 {
+		t.Fatalf(`unexpected error %#v`, countErr)
+	}
+}
+
+func TestReadRecordLengthLimits(t *testing.T) {
+	orig, err := ioutil.ReadFile(`../_test_files/point.shp`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	open := func(b []byte) *ShapeFile {
+		r, err := common.NewReadSeekCloser(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sf := &ShapeFile{r: r}
+		err = sf.Initialize()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return sf
+	}
+
+	sf := open(orig)
+	sf.SetMaxRecordSize(16)
+
+	_, _, err = sf.ReadRecord()
+	if _, ok := err.(*RecordTooLarge); !ok {
+		t.Fatalf(`expected RecordTooLarge, got %v`, err)
+	}
+
+	b := append([]byte{}, orig...)
+	binary.BigEndian.PutUint32(b[104:], 0x1000)
+
+	_, _, err = open(b).ReadRecord()
+	if _, ok := err.(*RecordPastEOF); !ok {
+		t.Fatalf(`expected RecordPastEOF, got %v`, err)
+	}
+	// 2^31 words and more must not wrap around to a small length
+	binary.BigEndian.PutUint32(b[104:], 0x8000000a)
+
+	_, _, err = open(b).ReadRecord()
+	if tooLarge, ok := err.(*RecordTooLarge); !ok || tooLarge.Length != 0x100000014 {
+		t.Fatalf(`expected RecordTooLarge, got %v`, err)
+	}
+
+	rec, err := open(b).ReadRawRecord()
+	if _, ok := err.(*RecordTooLarge); !ok {
+		t.Fatalf(`expected RecordTooLarge from ReadRawRecord, got %v`, err)
+	}
+
+	// Header is shown by shpdump even if length is invalid
+	if rec.Header.Number != 1 || rec.Header.Length != math.MaxUint32 || len(rec.RawHeader) != 8 || rec.Content != nil {
+		t.Fatalf(`unexpected raw record %+v`, rec)
+	}
+}
+
+// Content read before end of stream is returned with error
+func TestReadRawRecordTruncated(t *testing.T) {
+	b, err := ioutil.ReadFile(`../_test_files/point.shp`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sf := NewStream(bytes.NewReader(b[:100+8+5]))
+	err = sf.Initialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec, err := sf.ReadRawRecord()
+	if err != io.ErrUnexpectedEOF {
+		t.Fatalf(`expected io.ErrUnexpectedEOF, got %v`, err)
+	}
+
+	if rec.Header.Number != 1 || rec.Header.Length != 20 || len(rec.Content) != 5 {
+		t.Fatalf(`unexpected raw record %+v`, rec)
+	}
+}
+
+// First part not starting at point 0 is decoded, validate package reports it
+func TestDecodeRecordFirstPartNotZero(t *testing.T) {
+	content := make([]byte, 44+4+16*2)
+	binary.LittleEndian.PutUint32(content[0:], uint32(common.POLYLINE))
+	binary.LittleEndian.PutUint32(content[36:], 1)
+	binary.LittleEndian.PutUint32(content[40:], 2)
+	binary.LittleEndian.PutUint32(content[44:], 1)
+
+	shape, err := DecodeRecord(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if parts := shape.(PolyLine).Parts; len(parts) != 1 || parts[0] != 1 {
+		t.Fatalf(`parts are %v`, parts)
+	}
+}
+
+// Record header read with .shx entry must match it
+func TestReadIndexedRecordInvalidHeader(t *testing.T) {
+	orig, err := ioutil.ReadFile(`../_test_files/point.shp`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		fn   func(b []byte)
+	}{
+		{`number 0`, func(b []byte) { binary.BigEndian.PutUint32(b[100:], 0) }},
+		{`length wraps to index length`, func(b []byte) { binary.BigEndian.PutUint32(b[104:], 0x8000000a) }},
+	}
+
+	for _, tt := range tests {
+		b := append([]byte{}, orig...)
+		tt.fn(b)
+
+		r, err := common.NewReadSeekCloser(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sf := ShapeFile{r: r}
+		err = sf.Initialize()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		idx, _, err := sf.ReadIndexedRecord(100, 20)
+		if err == nil {
+			t.Errorf(`%v: expected error, got record #%d`, tt.name, idx)
+		}
+	}
+}
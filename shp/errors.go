@@ -0,0 +1,39 @@
+package shp
+
+import (
+	"fmt"
+)
+
+// Record content length in record header is larger than allowed, see SetMaxRecordSize
+type RecordTooLarge struct {
+	Number uint32 // 1-based, as in record header
+	Length int64  // Content length in bytes
+	Max    uint32
+}
+
+func (e *RecordTooLarge) Error() string {
+	return fmt.Sprintf(`record #%d content length %d is larger than maximum %d`, e.Number, e.Length, e.Max)
+}
+
+// Record content length in record header is larger than what is left in file
+type RecordPastEOF struct {
+	Number    uint32 // 1-based, as in record header
+	Length    int64  // Content length in bytes
+	Remaining int64  // Bytes left in file after record header
+}
+
+func (e *RecordPastEOF) Error() string {
+	return fmt.Sprintf(`record #%d content length %d is larger than remaining %d bytes of file`, e.Number, e.Length, e.Remaining)
+}
+
+// Count (NumParts, NumPoints) needs more bytes than there are left in record content
+type InvalidCount struct {
+	Field     string
+	Count     uint32
+	Need      int64 // Bytes needed for Count items
+	Remaining int64 // Bytes left in record content
+}
+
+func (e *InvalidCount) Error() string {
+	return fmt.Sprintf(`%v %d needs %d bytes but only %d bytes left in record`, e.Field, e.Count, e.Need, e.Remaining)
+}
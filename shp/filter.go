@@ -0,0 +1,68 @@
+package shp
+
+import (
+	"encoding/binary"
+	"errors"
+	"github.com/raspi/GeoESRIShapeFile/common"
+	"math"
+)
+
+// Returned internally for records skipped by box filter
+var errFiltered = errors.New(`record filtered`)
+
+/*
+Position Field      Value     Type    Number Order
+Byte 0   Shape Type ShapeType Integer 1      Little
+Byte 4   Box        Box       Double  4      Little   (Point types: X, Y)
+*/
+const (
+	recordBoxStart     = 4
+	recordBoxEnd       = recordBoxStart + 32
+	recordPointEnd     = recordBoxStart + 16
+	recordShapeTypeEnd = recordBoxStart
+)
+
+// Does box intersect with other box. Touching edges are counted as intersecting.
+func (b Box) Intersects(o Box) bool {
+	return b.MinX <= o.MaxX && b.MaxX >= o.MinX && b.MinY <= o.MaxY && b.MaxY >= o.MinY
+}
+
+// Does box contain point
+func (b Box) Contains(p Point) bool {
+	return p.X >= b.MinX && p.X <= b.MaxX && p.Y >= b.MinY && p.Y <= b.MaxY
+}
+
+// Get bounding box from start of raw record content without decoding rest of the record.
+// Null shapes get an empty box which intersects nothing. ok is false if box can't be determined.
+func recordBox(data []byte) (b Box, ok bool) {
+	if len(data) < recordShapeTypeEnd {
+		return b, false
+	}
+
+	f := func(off int) float64 {
+		return math.Float64frombits(binary.LittleEndian.Uint64(data[off:]))
+	}
+
+	switch common.ShapeType(binary.LittleEndian.Uint32(data)) {
+	case common.NULL:
+		return Box{MinX: math.Inf(1), MinY: math.Inf(1), MaxX: math.Inf(-1), MaxY: math.Inf(-1)}, true
+	case common.POINT, common.POINTM, common.POINTZ:
+		if len(data) < recordPointEnd {
+			return b, false
+		}
+
+		x, y := f(recordBoxStart), f(recordBoxStart+8)
+		return Box{MinX: x, MinY: y, MaxX: x, MaxY: y}, true
+	default:
+		if len(data) < recordBoxEnd {
+			return b, false
+		}
+
+		return Box{
+			MinX: f(recordBoxStart),
+			MinY: f(recordBoxStart + 8),
+			MaxX: f(recordBoxStart + 16),
+			MaxY: f(recordBoxStart + 24),
+		}, true
+	}
+}
@@ -0,0 +1,85 @@
+package shp
+
+import (
+	"io"
+	"testing"
+)
+
+func TestBoxIntersects(t *testing.T) {
+	b := Box{MinX: 0, MinY: 0, MaxX: 10, MaxY: 10}
+
+	tests := []struct {
+		other    Box
+		expected bool
+	}{
+		{Box{MinX: 5, MinY: 5, MaxX: 15, MaxY: 15}, true},
+		{Box{MinX: 10, MinY: 10, MaxX: 15, MaxY: 15}, true},
+		{Box{MinX: 2, MinY: 2, MaxX: 3, MaxY: 3}, true},
+		{Box{MinX: 11, MinY: 0, MaxX: 15, MaxY: 10}, false},
+		{Box{MinX: 0, MinY: -5, MaxX: 10, MaxY: -1}, false},
+	}
+
+	for _, tt := range tests {
+		if b.Intersects(tt.other) != tt.expected {
+			t.Fatalf(`%v intersects %v should be %v`, b, tt.other, tt.expected)
+		}
+	}
+}
+
+func TestReadRecordWithBoxFilter(t *testing.T) {
+	sf, err := New(`../_test_files/polylinez.shp`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sf.Close()
+
+	err = sf.Initialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sf.SetBoxFilter(&Box{MinX: 20, MinY: 20, MaxX: 30, MaxY: 30})
+
+	var found []uint32
+	for {
+		idx, _, err := sf.ReadRecord()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		found = append(found, idx)
+	}
+
+	if len(found) != 1 || found[0] != 1 {
+		t.Fatalf(`found records %v, should be [1]`, found)
+	}
+}
+
+// Filtered out records must be skipped without allocating their content
+func TestBoxFilterSkipAllocs(t *testing.T) {
+	// Records are larger than pooled buffers
+	sf := openInitialized(t, writePolygons(t, 5, 70000))
+	defer sf.Close()
+
+	sf.SetBoxFilter(&Box{MinX: 10, MinY: 10, MaxX: 20, MaxY: 20})
+
+	allocs := testing.AllocsPerRun(10, func() {
+		_, err := sf.r.Seek(100, io.SeekStart)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, _, err = sf.ReadRecord()
+		if err != io.EOF {
+			t.Fatalf(`expected io.EOF, got %v`, err)
+		}
+	})
+
+	if allocs != 0 {
+		t.Errorf(`skipping records allocated %v times, should be 0`, allocs)
+	}
+}
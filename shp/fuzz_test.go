@@ -0,0 +1,61 @@
+package shp
+
+import (
+	"github.com/raspi/GeoESRIShapeFile/common"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func FuzzReadRecord(f *testing.F) {
+	files, err := filepath.Glob(`../_test_files/*.shp`)
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	for _, fpath := range files {
+		b, err := ioutil.ReadFile(fpath)
+		if err != nil {
+			f.Fatal(err)
+		}
+
+		f.Add(b, false)
+		f.Add(b, true)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte, lenient bool) {
+		r, err := common.NewReadSeekCloser(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sf := ShapeFile{r: r}
+		sf.SetMaxRecordSize(1024 * 1024)
+
+		err = sf.Initialize()
+		if err != nil {
+			return
+		}
+
+		sf.SetLenient(lenient, nil)
+
+		// Every record is at least 12 bytes, so there can't be more records than this
+		for i := 0; i <= len(data)/12; i++ {
+			_, _, err = sf.ReadRecord()
+			if err == io.EOF {
+				return
+			}
+
+			if err != nil {
+				if lenient {
+					t.Fatalf(`lenient mode returned error: %v`, err)
+				}
+
+				return
+			}
+		}
+
+		t.Fatalf(`ReadRecord doesn't advance`)
+	})
+}
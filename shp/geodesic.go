@@ -0,0 +1,245 @@
+package shp
+
+import (
+	"math"
+)
+
+/*
+Geodesic measures for lon/lat data (X is longitude and Y is latitude in degrees).
+
+Lengths use Vincenty's inverse formula on the ellipsoid. Areas use spherical excess on the
+authalic sphere, which has the same surface area as the ellipsoid. Rings must not contain a pole.
+*/
+
+type Ellipsoid struct {
+	A float64 // Semi-major axis in meters
+	F float64 // Flattening
+}
+
+var (
+	WGS84 = Ellipsoid{A: 6378137, F: 1 / 298.257223563}
+	GRS80 = Ellipsoid{A: 6378137, F: 1 / 298.257222101}
+)
+
+const (
+	vincentyMaxIterations = 200
+	vincentyTolerance     = 1e-12
+)
+
+func radians(d float64) float64 {
+	return d * math.Pi / 180
+}
+
+// Semi-minor axis
+func (e Ellipsoid) B() float64 {
+	return e.A * (1 - e.F)
+}
+
+// Distance in meters between two lon/lat points
+func (e Ellipsoid) Distance(p1, p2 Point) float64 {
+	if p1 == p2 {
+		return 0
+	}
+
+	a, b, f := e.A, e.B(), e.F
+
+	L := radians(p2.X - p1.X)
+	U1 := math.Atan((1 - f) * math.Tan(radians(p1.Y)))
+	U2 := math.Atan((1 - f) * math.Tan(radians(p2.Y)))
+	sinU1, cosU1 := math.Sincos(U1)
+	sinU2, cosU2 := math.Sincos(U2)
+
+	lambda := L
+	var sinSigma, cosSigma, sigma, cosSqAlpha, cos2SigmaM float64
+
+	converged := false
+	for i := 0; i < vincentyMaxIterations; i++ {
+		sinLambda, cosLambda := math.Sincos(lambda)
+
+		sinSigma = math.Hypot(cosU2*sinLambda, cosU1*sinU2-sinU1*cosU2*cosLambda)
+		if sinSigma == 0 {
+			// Coincident points
+			return 0
+		}
+
+		cosSigma = sinU1*sinU2 + cosU1*cosU2*cosLambda
+		sigma = math.Atan2(sinSigma, cosSigma)
+
+		sinAlpha := cosU1 * cosU2 * sinLambda / sinSigma
+		cosSqAlpha = 1 - sinAlpha*sinAlpha
+
+		cos2SigmaM = 0
+		if cosSqAlpha != 0 {
+			// Not on equatorial line
+			cos2SigmaM = cosSigma - 2*sinU1*sinU2/cosSqAlpha
+		}
+
+		C := f / 16 * cosSqAlpha * (4 + f*(4-3*cosSqAlpha))
+		prev := lambda
+		lambda = L + (1-C)*f*sinAlpha*(sigma+C*sinSigma*(cos2SigmaM+C*cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)))
+
+		if math.Abs(lambda-prev) < vincentyTolerance {
+			converged = true
+			break
+		}
+	}
+
+	if !converged {
+		// Nearly antipodal points, fall back to great circle on authalic sphere
+		return e.greatCircleDistance(p1, p2)
+	}
+
+	uSq := cosSqAlpha * (a*a - b*b) / (b * b)
+	A := 1 + uSq/16384*(4096+uSq*(-768+uSq*(320-175*uSq)))
+	B := uSq / 1024 * (256 + uSq*(-128+uSq*(74-47*uSq)))
+	deltaSigma := B * sinSigma * (cos2SigmaM + B/4*(cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)-
+		B/6*cos2SigmaM*(-3+4*sinSigma*sinSigma)*(-3+4*cos2SigmaM*cos2SigmaM)))
+
+	return b * A * (sigma - deltaSigma)
+}
+
+func (e Ellipsoid) greatCircleDistance(p1, p2 Point) float64 {
+	lat1, lat2 := radians(p1.Y), radians(p2.Y)
+	dlat := lat2 - lat1
+	dlon := radians(p2.X - p1.X)
+
+	h := math.Sin(dlat/2)*math.Sin(dlat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dlon/2)*math.Sin(dlon/2)
+
+	return 2 * e.authalicRadius() * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// Eccentricity squared
+func (e Ellipsoid) e2() float64 {
+	return e.F * (2 - e.F)
+}
+
+func (e Ellipsoid) q(phi float64) float64 {
+	e2 := e.e2()
+	if e2 == 0 {
+		return 2 * math.Sin(phi)
+	}
+
+	ecc := math.Sqrt(e2)
+	s := math.Sin(phi)
+
+	return (1 - e2) * (s/(1-e2*s*s) - 1/(2*ecc)*math.Log((1-ecc*s)/(1+ecc*s)))
+}
+
+// Radius of sphere with same surface area as ellipsoid
+func (e Ellipsoid) authalicRadius() float64 {
+	return e.A * math.Sqrt(e.q(math.Pi/2)/2)
+}
+
+// Latitude on authalic sphere
+func (e Ellipsoid) authalicLatitude(phi float64) float64 {
+	return math.Asin(math.Max(-1, math.Min(1, e.q(phi)/e.q(math.Pi/2))))
+}
+
+// Signed area of ring in square meters, positive for clockwise. Sign is opposite to planar ringSignedArea.
+func (e Ellipsoid) ringSignedArea(ring []Point) float64 {
+	if len(ring) < 3 {
+		return 0
+	}
+
+	excess := 0.0
+
+	for i := 0; i < len(ring); i++ {
+		a := ring[i]
+		b := ring[(i+1)%len(ring)]
+
+		dlon := radians(b.X - a.X)
+		// Normalize to -180..180 for rings crossing the antimeridian
+		dlon = math.Remainder(dlon, 2*math.Pi)
+
+		t1 := math.Tan(e.authalicLatitude(radians(a.Y)) / 2)
+		t2 := math.Tan(e.authalicLatitude(radians(b.Y)) / 2)
+
+		excess += 2 * math.Atan2(math.Tan(dlon/2)*(t1+t2), 1+t1*t2)
+	}
+
+	r := e.authalicRadius()
+
+	return excess * r * r
+}
+
+func (e Ellipsoid) lineLength(line []Point) (l float64) {
+	for i := 1; i < len(line); i++ {
+		l += e.Distance(line[i-1], line[i])
+	}
+
+	return l
+}
+
+func (e Ellipsoid) ringLength(ring []Point) float64 {
+	l := e.lineLength(ring)
+
+	if len(ring) > 1 && ring[0] != ring[len(ring)-1] {
+		l += e.Distance(ring[len(ring)-1], ring[0])
+	}
+
+	return l
+}
+
+func (e Ellipsoid) polygonArea(rings [][]Point) float64 {
+	sum := 0.0
+	for _, ring := range rings {
+		sum += e.ringSignedArea(ring)
+	}
+
+	return math.Abs(sum)
+}
+
+func (e Ellipsoid) polygonPerimeter(rings [][]Point) (l float64) {
+	for _, ring := range rings {
+		l += e.ringLength(ring)
+	}
+
+	return l
+}
+
+func (e Ellipsoid) linesLength(lines [][]Point) (l float64) {
+	for _, line := range lines {
+		l += e.lineLength(line)
+	}
+
+	return l
+}
+
+// Area in square meters on ellipsoid, holes are subtracted
+func (p Polygon) GeodesicArea(e Ellipsoid) float64 {
+	return e.polygonArea(splitParts(p.Parts, p.Points))
+}
+
+// Length of all rings in meters on ellipsoid
+func (p Polygon) GeodesicPerimeter(e Ellipsoid) float64 {
+	return e.polygonPerimeter(splitParts(p.Parts, p.Points))
+}
+
+func (p PolygonM) GeodesicArea(e Ellipsoid) float64 {
+	return e.polygonArea(splitParts(p.Parts, p.Points))
+}
+
+func (p PolygonM) GeodesicPerimeter(e Ellipsoid) float64 {
+	return e.polygonPerimeter(splitParts(p.Parts, p.Points))
+}
+
+func (p PolygonZ) GeodesicArea(e Ellipsoid) float64 {
+	return e.polygonArea(splitParts(p.Parts, p.Points))
+}
+
+func (p PolygonZ) GeodesicPerimeter(e Ellipsoid) float64 {
+	return e.polygonPerimeter(splitParts(p.Parts, p.Points))
+}
+
+// Length of all parts in meters on ellipsoid
+func (p PolyLine) GeodesicLength(e Ellipsoid) float64 {
+	return e.linesLength(splitParts(p.Parts, p.Points))
+}
+
+func (p PolyLineM) GeodesicLength(e Ellipsoid) float64 {
+	return e.linesLength(splitParts(p.Parts, p.Points))
+}
+
+func (p PolyLineZ) GeodesicLength(e Ellipsoid) float64 {
+	return e.linesLength(splitParts(p.Parts, p.Points))
+}
@@ -0,0 +1,148 @@
+package shp
+
+import (
+	"encoding/binary"
+	"github.com/raspi/GeoESRIShapeFile/common"
+	"math"
+)
+
+/*
+Geometry holds any shape type so that one value can be reused for every record, see ReadRecordInto.
+Slices are resized in place, so after the first few records decoding doesn't allocate. Slices are
+overwritten by next decode, use Shape for a copy which can be kept.
+
+Point types have one point in Points, and their Z and M in ZArray and MArray. Types without parts
+have empty Parts, and types without Z or M have empty ZArray or MArray.
+*/
+type Geometry struct {
+	ShapeType common.ShapeType
+	Box       Box // Point types have box of single point and Null an empty box
+	Parts     []uint32
+	PartTypes []PartType // Multi patch only
+	Points    []Point
+	ZRange    [2]float64
+	ZArray    []float64
+	MRange    [2]float64
+	MArray    []float64
+	HasM      bool // M is optional in Z and M types
+}
+
+// Decode and validate record content (without record header) into g
+func DecodeInto(content []byte, g *Geometry) error {
+	v, err := NewRecordView(0, 0, content)
+	if err != nil {
+		return err
+	}
+
+	return v.DecodeInto(g)
+}
+
+// Decode and validate whole record into g, see Geometry
+func (v RecordView) DecodeInto(g *Geometry) error {
+	g.ShapeType = v.shapeType
+	g.Box = v.Box()
+	g.ZRange = [2]float64{}
+	g.MRange = [2]float64{}
+	g.HasM = v.HasM()
+
+	g.Parts = resize(g.Parts, v.numParts)
+	for i := range g.Parts {
+		g.Parts[i] = uint32(v.Part(i))
+	}
+
+	g.PartTypes = g.PartTypes[:0]
+	if v.typesAt > 0 {
+		g.PartTypes = resize(g.PartTypes, v.numParts)
+		for i := range g.PartTypes {
+			g.PartTypes[i] = v.PartType(i)
+		}
+	}
+
+	g.Points = resize(g.Points, v.numPoints)
+	for i := range g.Points {
+		g.Points[i] = v.Point(i)
+	}
+
+	// Point types have single values without range
+	ranged := v.pointsAt != 4
+
+	g.ZArray = g.ZArray[:0]
+	if v.HasZ() {
+		g.ZArray = resize(g.ZArray, v.numPoints)
+		for i := range g.ZArray {
+			g.ZArray[i] = v.Z(i)
+		}
+
+		if ranged {
+			g.ZRange = v.readRange(v.zAt - 16)
+		}
+	}
+
+	g.MArray = g.MArray[:0]
+	if v.HasM() {
+		g.MArray = resize(g.MArray, v.numPoints)
+		for i := range g.MArray {
+			g.MArray[i] = v.M(i)
+		}
+
+		if ranged {
+			g.MRange = v.readRange(v.mAt - 16)
+		}
+	}
+
+	if v.partsAt > 0 {
+		return validateParts(uint32(v.numParts), uint32(v.numPoints), g.Parts, g.Points)
+	}
+
+	return nil
+}
+
+func (v RecordView) readRange(at int) (rng [2]float64) {
+	rng[0] = math.Float64frombits(binary.LittleEndian.Uint64(v.Content[at:]))
+	rng[1] = math.Float64frombits(binary.LittleEndian.Uint64(v.Content[at+8:]))
+	return rng
+}
+
+// Copy of geometry as same shape type as ReadRecord returns
+func (g *Geometry) Shape() ShapeTypeI {
+	var m []float64
+	if g.HasM {
+		m = clone(g.MArray)
+	}
+
+	switch g.ShapeType {
+	case common.POINT:
+		return g.Points[0]
+	case common.POINTM:
+		return PointM{X: g.Points[0].X, Y: g.Points[0].Y, M: g.MArray[0]}
+	case common.POINTZ:
+		p := PointZ{X: g.Points[0].X, Y: g.Points[0].Y, Z: g.ZArray[0]}
+		if g.HasM {
+			p.M = g.MArray[0]
+		}
+
+		return p
+	case common.MULTIPOINT:
+		return MultiPoint{Box: g.Box, NumPoints: uint32(len(g.Points)), Points: clone(g.Points)}
+	case common.MULTIPOINTM:
+		return MultiPointM{Box: g.Box, NumPoints: uint32(len(g.Points)), Points: clone(g.Points), MRange: g.MRange, MArray: m}
+	case common.MULTIPOINTZ:
+		return MultiPointZ{Box: g.Box, NumPoints: uint32(len(g.Points)), Points: clone(g.Points), ZRange: g.ZRange, ZArray: clone(g.ZArray), MRange: g.MRange, MArray: m}
+	case common.POLYLINE:
+		return PolyLine{Box: g.Box, NumParts: uint32(len(g.Parts)), NumPoints: uint32(len(g.Points)), Parts: clone(g.Parts), Points: clone(g.Points)}
+	case common.POLYLINEM:
+		return PolyLineM{Box: g.Box, NumParts: uint32(len(g.Parts)), NumPoints: uint32(len(g.Points)), Parts: clone(g.Parts), Points: clone(g.Points), MRange: g.MRange, MArray: m}
+	case common.POLYLINEZ:
+		return PolyLineZ{Box: g.Box, NumParts: uint32(len(g.Parts)), NumPoints: uint32(len(g.Points)), Parts: clone(g.Parts), Points: clone(g.Points), ZRange: g.ZRange, ZArray: clone(g.ZArray), MRange: g.MRange, MArray: m}
+	case common.POLYGON:
+		return Polygon{Box: g.Box, NumParts: uint32(len(g.Parts)), NumPoints: uint32(len(g.Points)), Parts: clone(g.Parts), Points: clone(g.Points)}
+	case common.POLYGONM:
+		return PolygonM{Box: g.Box, NumParts: uint32(len(g.Parts)), NumPoints: uint32(len(g.Points)), Parts: clone(g.Parts), Points: clone(g.Points), MRange: g.MRange, MArray: m}
+	case common.POLYGONZ:
+		return PolygonZ{Box: g.Box, NumParts: uint32(len(g.Parts)), NumPoints: uint32(len(g.Points)), Parts: clone(g.Parts), Points: clone(g.Points), ZRange: g.ZRange, ZArray: clone(g.ZArray), MRange: g.MRange, MArray: m}
+	case common.MULTIPATCH:
+		return MultiPatch{Box: g.Box, NumParts: uint32(len(g.Parts)), NumPoints: uint32(len(g.Points)), Parts: clone(g.Parts), PartTypes: clone(g.PartTypes), Points: clone(g.Points), ZRange: g.ZRange, ZArray: clone(g.ZArray), MRange: g.MRange, MArray: m}
+	default:
+		return Null{}
+	}
+}
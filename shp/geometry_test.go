@@ -0,0 +1,101 @@
+package shp
+
+import (
+	"io"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func openInitialized(tb testing.TB, fpath string) ShapeFile {
+	sf, err := New(fpath)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	err = sf.Initialize()
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return sf
+}
+
+func TestReadRecordInto(t *testing.T) {
+	files, err := filepath.Glob(`../_test_files/*.shp`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, fpath := range files {
+		t.Run(filepath.Base(fpath), func(t *testing.T) {
+			want := openInitialized(t, fpath)
+			defer want.Close()
+
+			got := openInitialized(t, fpath)
+			defer got.Close()
+
+			// Same geometry for every record, so stale values from previous records would show up
+			var g Geometry
+
+			for {
+				n, shape, err := want.ReadRecord()
+				if err == io.EOF {
+					break
+				}
+
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				idx, err := got.ReadRecordInto(&g)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if idx != n {
+					t.Fatalf(`record number %d, should be %d`, idx, n)
+				}
+
+				if !reflect.DeepEqual(g.Shape(), shape) {
+					t.Errorf(`record #%d is %#v, should be %#v`, n, g.Shape(), shape)
+				}
+			}
+
+			_, err := got.ReadRecordInto(&g)
+			if err != io.EOF {
+				t.Fatalf(`expected io.EOF, got %v`, err)
+			}
+		})
+	}
+}
+
+func TestReadRecordIntoAllocs(t *testing.T) {
+	sf := openInitialized(t, writePolygons(t, 100, 50))
+	defer sf.Close()
+
+	var g Geometry
+
+	allocs := testing.AllocsPerRun(50, func() {
+		_, err := sf.ReadRecordInto(&g)
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	if allocs != 0 {
+		t.Errorf(`ReadRecordInto allocated %v times per record, should be 0`, allocs)
+	}
+}
+
+func TestDecodeIntoInvalidParts(t *testing.T) {
+	content := make([]byte, 44+4+16)
+	content[0] = 5                  // Polygon
+	content[36], content[40] = 1, 1 // 1 part, 1 point
+	content[44] = 1                 // Part starts past last point
+
+	var g Geometry
+	if err := DecodeInto(content, &g); err == nil {
+		t.Fatal(`expected error`)
+	}
+}
@@ -0,0 +1,47 @@
+//go:build go1.23
+
+package shp
+
+import (
+	"io"
+	"iter"
+)
+
+/*
+Iterator over records read with ReadRecord from current position, with same 0-based record number:
+
+	for n, shape := range sf.Shapes() {
+		...
+	}
+	if err := sf.Err(); err != nil {
+		...
+	}
+
+Iteration ends at end of file or at first error, see Err.
+*/
+func (sf *ShapeFile) Shapes() iter.Seq2[uint32, ShapeTypeI] {
+	return func(yield func(uint32, ShapeTypeI) bool) {
+		sf.iterErr = nil
+
+		for {
+			idx, record, err := sf.ReadRecord()
+			if err == io.EOF {
+				return
+			}
+
+			if err != nil {
+				sf.iterErr = err
+				return
+			}
+
+			if !yield(idx, record) {
+				return
+			}
+		}
+	}
+}
+
+// Error which stopped Shapes iterator, nil if it reached end of file or loop was exited with break
+func (sf *ShapeFile) Err() error {
+	return sf.iterErr
+}
@@ -0,0 +1,49 @@
+//go:build go1.23
+
+package shp
+
+import (
+	"encoding/binary"
+	"github.com/raspi/GeoESRIShapeFile/common"
+	"io/ioutil"
+	"testing"
+)
+
+func TestShapes(t *testing.T) {
+	data, err := ioutil.ReadFile(`../_test_files/point.shp`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Invalid shape type in third record
+	binary.LittleEndian.PutUint32(data[156+8:], 99)
+
+	f, err := common.NewReadSeekCloser(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sf := NewFrom(f)
+
+	err = sf.Initialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var numbers []uint32
+	for n, shape := range sf.Shapes() {
+		if _, ok := shape.(Point); !ok {
+			t.Errorf(`record #%d is %T`, n, shape)
+		}
+
+		numbers = append(numbers, n)
+	}
+
+	if len(numbers) != 2 || numbers[1] != 1 {
+		t.Errorf(`iterated records %v`, numbers)
+	}
+
+	if sf.Err() == nil {
+		t.Fatal(`expected error of third record`)
+	}
+}
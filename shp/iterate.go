@@ -0,0 +1,47 @@
+package shp
+
+import (
+	"context"
+	"github.com/raspi/GeoESRIShapeFile/common"
+	"io"
+)
+
+/*
+Call fn for every record read with ReadRecord, starting from current position, until end of file.
+Stops when ctx is cancelled, returning ctx.Err(), or when reading fails or fn returns an error.
+progress may be nil. Total records is not known from .shp alone, see geoesrishapefile ForEach.
+*/
+func (sf *ShapeFile) ForEach(ctx context.Context, fn func(idx uint32, record ShapeTypeI) error, progress common.ProgressFunc) error {
+	p := common.Progress{TotalRecords: -1, TotalBytes: int64(sf.Header1.Length) * 2}
+
+	for {
+		err := ctx.Err()
+		if err != nil {
+			return err
+		}
+
+		idx, record, err := sf.ReadRecord()
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		err = fn(idx, record)
+		if err != nil {
+			return err
+		}
+
+		if progress != nil {
+			p.Records++
+			p.Bytes, err = sf.r.Seek(0, io.SeekCurrent)
+			if err != nil {
+				return err
+			}
+
+			progress(p)
+		}
+	}
+}
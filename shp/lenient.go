@@ -0,0 +1,153 @@
+package shp
+
+import (
+	"encoding/binary"
+	"fmt"
+	"github.com/raspi/GeoESRIShapeFile/common"
+	"io"
+)
+
+const (
+	resyncChunkSize = 64 * 1024
+	resyncWindow    = 12 // Record header and shape type
+	resyncMaxSkip   = 16 // How many record numbers resync scan may jump over
+)
+
+// RecordIndex gives .shp offsets of records by 0-based record number. Implemented by shx.IndexRecordLookupFile.
+type RecordIndex interface {
+	RecordOffset(n uint32) (offset int64, err error)
+}
+
+// Corrupt record skipped in lenient mode
+type RecordError struct {
+	Number uint32 // 0-based position in file, same as returned by ReadRecord for valid records
+	Offset int64  // Offset of record header in .shp
+	Err    error
+}
+
+func (e *RecordError) Error() string {
+	return fmt.Sprintf(`record #%d at offset 0x%04[2]x (%06[2]d): %[3]v`, e.Number, e.Offset, e.Err)
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+/*
+In lenient mode ReadRecord skips records which fail to read or validate instead of returning
+the error. Errors are collected, see RecordErrors. Next record is found with index if it's
+not nil. Otherwise it's right after skipped record if record header was valid, and found by
+scanning for next plausible record header if it wasn't.
+
+Skipped records are missing from the stream, so use record number returned by ReadRecord to
+read matching dBase row.
+*/
+func (sf *ShapeFile) SetLenient(flag bool, index RecordIndex) {
+	sf.lenient = flag
+	sf.index = index
+}
+
+func (sf ShapeFile) GetLenient() bool {
+	return sf.lenient
+}
+
+// Errors of records skipped in lenient mode
+func (sf ShapeFile) RecordErrors() []*RecordError {
+	return sf.recordErrors
+}
+
+// Record failed in lenient mode, move to next record. length is content length from valid record
+// header, -1 if header was invalid. Returns io.EOF if there is no next record.
+func (sf *ShapeFile) skipRecord(number uint32, offset, length int64, err error) error {
+	recErr := &RecordError{Number: number, Offset: offset, Err: err}
+	sf.recordErrors = append(sf.recordErrors, recErr)
+
+	if sf.logger != nil {
+		sf.logger.Warn(`skipping corrupt record`, `number`, number, `offset`, offset, `error`, err)
+	}
+
+	next := int64(-1)
+
+	if sf.index != nil {
+		next, err = sf.index.RecordOffset(sf.next)
+		if err != nil || next <= offset {
+			// Past end of index or index is broken too. Scanning finds the end quickly if there really are no more records.
+			next = -1
+		}
+	}
+
+	if next < 0 && length >= 0 {
+		// Header was valid, only content was broken
+		next = offset + 8 + length
+	}
+
+	if next < 0 {
+		next, err = sf.resync(offset + 8)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = sf.r.Seek(next, io.SeekStart)
+	return err
+}
+
+// Scan for next plausible record header starting from offset
+func (sf *ShapeFile) resync(offset int64) (next int64, err error) {
+	size, err := sf.r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+
+	buf := make([]byte, resyncChunkSize)
+
+	for offset+resyncWindow <= size {
+		n := int64(len(buf))
+		if size-offset < n {
+			n = size - offset
+		}
+
+		_, err = sf.r.Seek(offset, io.SeekStart)
+		if err != nil {
+			return 0, err
+		}
+
+		_, err = io.ReadFull(sf.r, buf[:n])
+		if err != nil {
+			return 0, err
+		}
+
+		for i := int64(0); i+resyncWindow <= n; i++ {
+			if number, ok := sf.plausibleHeader(buf[i:i+resyncWindow], offset+i, size); ok {
+				sf.next = number
+				return offset + i, nil
+			}
+		}
+
+		// Overlap chunks so that headers crossing chunk boundary are found
+		offset += n - resyncWindow + 1
+	}
+
+	return 0, io.EOF
+}
+
+// Could data be a record header followed by shape type. number is 0-based.
+func (sf *ShapeFile) plausibleHeader(data []byte, offset, size int64) (number uint32, ok bool) {
+	number = binary.BigEndian.Uint32(data[0:])
+	length := common.WordsToBytes(data[4:])
+	st := common.ShapeType(binary.LittleEndian.Uint32(data[8:]))
+
+	if number <= sf.next || number > sf.next+resyncMaxSkip {
+		return 0, false
+	}
+
+	if length < 4 || offset+8+length > size {
+		return 0, false
+	}
+
+	if st != common.NULL && st != sf.Header2.ShapeType {
+		return 0, false
+	}
+
+	return number - 1, true
+}
@@ -0,0 +1,119 @@
+package shp
+
+import (
+	"encoding/binary"
+	"github.com/raspi/GeoESRIShapeFile/common"
+	"io"
+	"io/ioutil"
+	"testing"
+)
+
+// Offsets of record headers in _test_files/point.shp
+type pointIndex []int64
+
+func (pi pointIndex) RecordOffset(n uint32) (int64, error) {
+	if int(n) >= len(pi) {
+		return 0, io.EOF
+	}
+
+	return pi[n], nil
+}
+
+func TestLenientReadRecord(t *testing.T) {
+	orig, err := ioutil.ReadFile(`../_test_files/point.shp`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const second = 128 // Second record header
+
+	tests := []struct {
+		name  string
+		index RecordIndex
+		fn    func(b []byte)
+	}{
+		{`invalid shape type`, nil, func(b []byte) { binary.LittleEndian.PutUint32(b[second+8:], 99) }},
+		{`invalid shape type, header pattern in content`, nil, func(b []byte) {
+			// Scanning content would find NULL record #3 at second+4
+			binary.LittleEndian.PutUint32(b[second+8:], 99)
+			binary.BigEndian.PutUint32(b[second+12:], 3)
+			binary.BigEndian.PutUint32(b[second+16:], 2)
+			binary.LittleEndian.PutUint32(b[second+20:], 0)
+		}},
+		{`length past end of file`, nil, func(b []byte) { binary.BigEndian.PutUint32(b[second+4:], 0x100) }},
+		{`zero content length`, nil, func(b []byte) { binary.BigEndian.PutUint32(b[second+4:], 0) }},
+		{`invalid shape type with index`, pointIndex{100, 128, 156}, func(b []byte) { binary.LittleEndian.PutUint32(b[second+8:], 99) }},
+	}
+
+	for _, tt := range tests {
+		b := append([]byte{}, orig...)
+		tt.fn(b)
+
+		r, err := common.NewReadSeekCloser(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sf := ShapeFile{r: r}
+		err = sf.Initialize()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sf.SetLenient(true, tt.index)
+
+		var found []uint32
+		for {
+			idx, _, err := sf.ReadRecord()
+			if err == io.EOF {
+				break
+			}
+
+			if err != nil {
+				t.Fatalf(`%v: %v`, tt.name, err)
+			}
+
+			found = append(found, idx)
+		}
+
+		if len(found) != 2 || found[0] != 0 || found[1] != 2 {
+			t.Errorf(`%v: found records %v, should be [0 2]`, tt.name, found)
+		}
+
+		errs := sf.RecordErrors()
+		if len(errs) != 1 || errs[0].Number != 1 || errs[0].Offset != second {
+			t.Errorf(`%v: expected error for record #1 at %d, got %v`, tt.name, second, errs)
+		}
+	}
+}
+
+// Truncated record content is an error, not end of file
+func TestReadRecordZeroLength(t *testing.T) {
+	b, err := ioutil.ReadFile(`../_test_files/point.shp`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	binary.BigEndian.PutUint32(b[128+4:], 0)
+
+	r, err := common.NewReadSeekCloser(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sf := ShapeFile{r: r}
+	err = sf.Initialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err = sf.ReadRecord()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err = sf.ReadRecord()
+	if err != io.ErrUnexpectedEOF {
+		t.Fatalf(`expected io.ErrUnexpectedEOF, got %v`, err)
+	}
+}
@@ -0,0 +1,423 @@
+package shp
+
+import (
+	"math"
+	"sort"
+)
+
+/*
+Planar measures. Coordinates are used as-is, so results are in units of the coordinate system.
+For lon/lat data see geodesic.go.
+
+Outer rings of polygons are clockwise and holes counter-clockwise. Area is computed from signed
+ring areas so holes are subtracted.
+*/
+
+// Split points to parts
+func splitParts(parts []uint32, points []Point) (lines [][]Point) {
+	if len(parts) == 0 {
+		if len(points) == 0 {
+			return nil
+		}
+
+		return [][]Point{points}
+	}
+
+	lines = make([][]Point, 0, len(parts))
+
+	for idx, start := range parts {
+		end := uint32(len(points))
+		if idx+1 < len(parts) {
+			end = parts[idx+1]
+		}
+
+		if start > end || end > uint32(len(points)) {
+			// Invalid parts, see Validate()
+			continue
+		}
+
+		lines = append(lines, points[start:end])
+	}
+
+	return lines
+}
+
+// Signed area of ring with shoelace formula, positive for counter-clockwise.
+// Coordinates are shifted to first point for numerical stability. Ring may be closed or open.
+func RingSignedArea(ring []Point) float64 {
+	if len(ring) < 3 {
+		return 0
+	}
+
+	o := ring[0]
+	sum := 0.0
+
+	for i := 0; i < len(ring); i++ {
+		a := ring[i]
+		b := ring[(i+1)%len(ring)]
+		sum += (a.X-o.X)*(b.Y-o.Y) - (b.X-o.X)*(a.Y-o.Y)
+	}
+
+	return sum / 2
+}
+
+// Signed area and centroid of ring
+func ringCentroid(ring []Point) (area float64, c Point) {
+	if len(ring) < 3 {
+		return 0, c
+	}
+
+	o := ring[0]
+	var cx, cy float64
+
+	for i := 0; i < len(ring); i++ {
+		a := ring[i]
+		b := ring[(i+1)%len(ring)]
+
+		ax, ay := a.X-o.X, a.Y-o.Y
+		bx, by := b.X-o.X, b.Y-o.Y
+
+		cross := ax*by - bx*ay
+		area += cross
+		cx += (ax + bx) * cross
+		cy += (ay + by) * cross
+	}
+
+	area /= 2
+	if area == 0 {
+		return 0, c
+	}
+
+	return area, Point{X: o.X + cx/(6*area), Y: o.Y + cy/(6*area)}
+}
+
+func lineLength(line []Point) (l float64) {
+	for i := 1; i < len(line); i++ {
+		l += math.Hypot(line[i].X-line[i-1].X, line[i].Y-line[i-1].Y)
+	}
+
+	return l
+}
+
+func linesLength(lines [][]Point) (l float64) {
+	for _, line := range lines {
+		l += lineLength(line)
+	}
+
+	return l
+}
+
+// Closing segment is added if ring is not closed
+func ringLength(ring []Point) float64 {
+	l := lineLength(ring)
+
+	if len(ring) > 1 && ring[0] != ring[len(ring)-1] {
+		l += math.Hypot(ring[0].X-ring[len(ring)-1].X, ring[0].Y-ring[len(ring)-1].Y)
+	}
+
+	return l
+}
+
+func polygonArea(rings [][]Point) float64 {
+	sum := 0.0
+	for _, ring := range rings {
+		sum += RingSignedArea(ring)
+	}
+
+	// Clockwise outer rings give negative sum. Abs() also handles files with reversed orientation.
+	return math.Abs(sum)
+}
+
+func polygonPerimeter(rings [][]Point) (l float64) {
+	for _, ring := range rings {
+		l += ringLength(ring)
+	}
+
+	return l
+}
+
+func polygonCentroid(rings [][]Point) Point {
+	var area, cx, cy float64
+
+	for _, ring := range rings {
+		a, c := ringCentroid(ring)
+		area += a
+		cx += c.X * a
+		cy += c.Y * a
+	}
+
+	if area == 0 {
+		// Degenerate polygon, use rings as lines
+		return linesCentroid(rings)
+	}
+
+	return Point{X: cx / area, Y: cy / area}
+}
+
+// Length weighted centroid of line segments
+func linesCentroid(lines [][]Point) Point {
+	var total, cx, cy float64
+
+	for _, line := range lines {
+		for i := 1; i < len(line); i++ {
+			a, b := line[i-1], line[i]
+			l := math.Hypot(b.X-a.X, b.Y-a.Y)
+			total += l
+			cx += (a.X + b.X) / 2 * l
+			cy += (a.Y + b.Y) / 2 * l
+		}
+	}
+
+	if total == 0 {
+		// Zero length, use points
+		var points []Point
+		for _, line := range lines {
+			points = append(points, line...)
+		}
+
+		return pointsCentroid(points)
+	}
+
+	return Point{X: cx / total, Y: cy / total}
+}
+
+func pointsCentroid(points []Point) (c Point) {
+	if len(points) == 0 {
+		return Point{X: math.NaN(), Y: math.NaN()}
+	}
+
+	for _, p := range points {
+		c.X += p.X
+		c.Y += p.Y
+	}
+
+	c.X /= float64(len(points))
+	c.Y /= float64(len(points))
+
+	return c
+}
+
+// Point nearest to centroid
+func nearestPoint(points []Point, c Point) Point {
+	if len(points) == 0 {
+		return Point{X: math.NaN(), Y: math.NaN()}
+	}
+
+	best := points[0]
+	bestDist := math.Inf(1)
+
+	for _, p := range points {
+		d := (p.X-c.X)*(p.X-c.X) + (p.Y-c.Y)*(p.Y-c.Y)
+		if d < bestDist {
+			best, bestDist = p, d
+		}
+	}
+
+	return best
+}
+
+func linesPointOnSurface(lines [][]Point) Point {
+	var points []Point
+	for _, line := range lines {
+		points = append(points, line...)
+	}
+
+	return nearestPoint(points, linesCentroid(lines))
+}
+
+/*
+Interior point of polygon. Horizontal scan line is placed between vertices nearest to the middle
+of the bounding box so that it never passes through a vertex. Crossings of all rings are sorted
+and widest inside interval (even-odd rule, so holes are excluded) is used.
+*/
+func polygonPointOnSurface(rings [][]Point) Point {
+	minY, maxY := math.Inf(1), math.Inf(-1)
+	for _, ring := range rings {
+		for _, p := range ring {
+			minY = math.Min(minY, p.Y)
+			maxY = math.Max(maxY, p.Y)
+		}
+	}
+
+	centre := (minY + maxY) / 2
+	lo, hi := minY, maxY
+
+	for _, ring := range rings {
+		for _, p := range ring {
+			if p.Y <= centre && p.Y > lo {
+				lo = p.Y
+			}
+
+			if p.Y > centre && p.Y < hi {
+				hi = p.Y
+			}
+		}
+	}
+
+	scanY := (lo + hi) / 2
+
+	var crossings []float64
+	for _, ring := range rings {
+		for i := 0; i < len(ring); i++ {
+			a := ring[i]
+			b := ring[(i+1)%len(ring)]
+
+			if (a.Y > scanY) == (b.Y > scanY) {
+				continue
+			}
+
+			crossings = append(crossings, a.X+(scanY-a.Y)*(b.X-a.X)/(b.Y-a.Y))
+		}
+	}
+
+	sort.Float64s(crossings)
+
+	best := -1.0
+	var p Point
+
+	for i := 0; i+1 < len(crossings); i += 2 {
+		w := crossings[i+1] - crossings[i]
+		if w > best {
+			best = w
+			p = Point{X: (crossings[i] + crossings[i+1]) / 2, Y: scanY}
+		}
+	}
+
+	if best <= 0 {
+		// Degenerate polygon, use rings as lines
+		return linesPointOnSurface(rings)
+	}
+
+	return p
+}
+
+// Polygon
+
+// Planar area, holes are subtracted
+func (p Polygon) Area() float64 {
+	return polygonArea(splitParts(p.Parts, p.Points))
+}
+
+// Planar length of all rings
+func (p Polygon) Perimeter() float64 {
+	return polygonPerimeter(splitParts(p.Parts, p.Points))
+}
+
+// Area weighted centroid. May be outside of polygon, see PointOnSurface.
+func (p Polygon) Centroid() Point {
+	return polygonCentroid(splitParts(p.Parts, p.Points))
+}
+
+// Point guaranteed to be inside polygon (not in a hole)
+func (p Polygon) PointOnSurface() Point {
+	return polygonPointOnSurface(splitParts(p.Parts, p.Points))
+}
+
+// PolygonM
+
+func (p PolygonM) Area() float64 {
+	return polygonArea(splitParts(p.Parts, p.Points))
+}
+
+func (p PolygonM) Perimeter() float64 {
+	return polygonPerimeter(splitParts(p.Parts, p.Points))
+}
+
+func (p PolygonM) Centroid() Point {
+	return polygonCentroid(splitParts(p.Parts, p.Points))
+}
+
+func (p PolygonM) PointOnSurface() Point {
+	return polygonPointOnSurface(splitParts(p.Parts, p.Points))
+}
+
+// PolygonZ, Z is not used
+
+func (p PolygonZ) Area() float64 {
+	return polygonArea(splitParts(p.Parts, p.Points))
+}
+
+func (p PolygonZ) Perimeter() float64 {
+	return polygonPerimeter(splitParts(p.Parts, p.Points))
+}
+
+func (p PolygonZ) Centroid() Point {
+	return polygonCentroid(splitParts(p.Parts, p.Points))
+}
+
+func (p PolygonZ) PointOnSurface() Point {
+	return polygonPointOnSurface(splitParts(p.Parts, p.Points))
+}
+
+// PolyLine
+
+// Planar length of all parts
+func (p PolyLine) Length() float64 {
+	return linesLength(splitParts(p.Parts, p.Points))
+}
+
+// Length weighted centroid
+func (p PolyLine) Centroid() Point {
+	return linesCentroid(splitParts(p.Parts, p.Points))
+}
+
+// Vertex nearest to centroid
+func (p PolyLine) PointOnSurface() Point {
+	return linesPointOnSurface(splitParts(p.Parts, p.Points))
+}
+
+// PolyLineM
+
+func (p PolyLineM) Length() float64 {
+	return linesLength(splitParts(p.Parts, p.Points))
+}
+
+func (p PolyLineM) Centroid() Point {
+	return linesCentroid(splitParts(p.Parts, p.Points))
+}
+
+func (p PolyLineM) PointOnSurface() Point {
+	return linesPointOnSurface(splitParts(p.Parts, p.Points))
+}
+
+// PolyLineZ, Z is not used
+
+func (p PolyLineZ) Length() float64 {
+	return linesLength(splitParts(p.Parts, p.Points))
+}
+
+func (p PolyLineZ) Centroid() Point {
+	return linesCentroid(splitParts(p.Parts, p.Points))
+}
+
+func (p PolyLineZ) PointOnSurface() Point {
+	return linesPointOnSurface(splitParts(p.Parts, p.Points))
+}
+
+// MultiPoint
+
+// Mean of points
+func (p MultiPoint) Centroid() Point {
+	return pointsCentroid(p.Points)
+}
+
+// Point nearest to centroid
+func (p MultiPoint) PointOnSurface() Point {
+	return nearestPoint(p.Points, pointsCentroid(p.Points))
+}
+
+func (p MultiPointM) Centroid() Point {
+	return pointsCentroid(p.Points)
+}
+
+func (p MultiPointM) PointOnSurface() Point {
+	return nearestPoint(p.Points, pointsCentroid(p.Points))
+}
+
+func (p MultiPointZ) Centroid() Point {
+	return pointsCentroid(p.Points)
+}
+
+func (p MultiPointZ) PointOnSurface() Point {
+	return nearestPoint(p.Points, pointsCentroid(p.Points))
+}
@@ -0,0 +1,162 @@
+package shp
+
+import (
+	"math"
+	"testing"
+)
+
+// 10 x 10 square (clockwise) with 2 x 2 hole (counter-clockwise) in the middle
+var testSquareWithHole = Polygon{
+	NumParts:  2,
+	NumPoints: 10,
+	Parts:     []uint32{0, 5},
+	Points: []Point{
+		{0, 0}, {0, 10}, {10, 10}, {10, 0}, {0, 0},
+		{4, 4}, {6, 4}, {6, 6}, {4, 6}, {4, 4},
+	},
+}
+
+func almostEqual(a, b, tolerance float64) bool {
+	return math.Abs(a-b) <= tolerance
+}
+
+func TestPolygonMeasures(t *testing.T) {
+	p := testSquareWithHole
+
+	if p.Area() != 96 {
+		t.Fatalf(`area was %v, should be 96`, p.Area())
+	}
+
+	if p.Perimeter() != 48 {
+		t.Fatalf(`perimeter was %v, should be 48`, p.Perimeter())
+	}
+
+	c := p.Centroid()
+	if !almostEqual(c.X, 5, 1e-9) || !almostEqual(c.Y, 5, 1e-9) {
+		t.Fatalf(`centroid was %v, should be 5 x 5`, c)
+	}
+
+	// Centroid is in the hole, point on surface must not be
+	s := p.PointOnSurface()
+	if s.X <= 0 || s.X >= 10 || s.Y <= 0 || s.Y >= 10 {
+		t.Fatalf(`point on surface %v is outside polygon`, s)
+	}
+
+	if s.X > 4 && s.X < 6 && s.Y > 4 && s.Y < 6 {
+		t.Fatalf(`point on surface %v is in hole`, s)
+	}
+}
+
+func TestPolygonPointOnSurfaceConcave(t *testing.T) {
+	// U shape, centroid is outside
+	p := Polygon{
+		NumParts:  1,
+		NumPoints: 9,
+		Parts:     []uint32{0},
+		Points:    []Point{{0, 0}, {0, 10}, {2, 10}, {2, 2}, {8, 2}, {8, 10}, {10, 10}, {10, 0}, {0, 0}},
+	}
+
+	s := p.PointOnSurface()
+	inLeft := s.X > 0 && s.X < 2 && s.Y > 0 && s.Y < 10
+	inRight := s.X > 8 && s.X < 10 && s.Y > 0 && s.Y < 10
+	inBottom := s.X > 0 && s.X < 10 && s.Y > 0 && s.Y < 2
+
+	if !inLeft && !inRight && !inBottom {
+		t.Fatalf(`point on surface %v is outside polygon`, s)
+	}
+}
+
+func TestPolyLineMeasures(t *testing.T) {
+	p := PolyLine{
+		NumParts:  2,
+		NumPoints: 5,
+		Parts:     []uint32{0, 3},
+		Points:    []Point{{0, 0}, {3, 4}, {3, 8}, {10, 10}, {10, 12}},
+	}
+
+	if p.Length() != 11 {
+		t.Fatalf(`length was %v, should be 11`, p.Length())
+	}
+
+	s := p.PointOnSurface()
+	found := false
+	for _, pt := range p.Points {
+		if pt == s {
+			found = true
+		}
+	}
+
+	if !found {
+		t.Fatalf(`point on surface %v is not a vertex`, s)
+	}
+}
+
+func TestGeodesicDistance(t *testing.T) {
+	// Flinders Peak to Buninyong, Vincenty (1975)
+	d := GRS80.Distance(Point{X: 144.42486788888889, Y: -37.95103341666667}, Point{X: 143.92649552777778, Y: -37.65282113888889})
+	if !almostEqual(d, 54972.271, 0.001) {
+		t.Fatalf(`distance was %v, should be 54972.271`, d)
+	}
+
+	// Nearly antipodal, must not fail
+	d = WGS84.Distance(Point{X: 0, Y: 0}, Point{X: 179.7, Y: 0.5})
+	if math.IsNaN(d) || d < 19900000 || d > 20010000 {
+		t.Fatalf(`antipodal distance was %v`, d)
+	}
+}
+
+func TestGeodesicArea(t *testing.T) {
+	// 1 x 1 degree at equator, clockwise
+	p := Polygon{
+		NumParts:  1,
+		NumPoints: 5,
+		Parts:     []uint32{0},
+		Points:    []Point{{0, 0}, {0, 1}, {1, 1}, {1, 0}, {0, 0}},
+	}
+
+	// GeographicLib: 12308778361 m²
+	a := p.GeodesicArea(WGS84)
+	if !almostEqual(a, 12308778361, 12308778361*0.001) {
+		t.Fatalf(`area was %v, should be about 12308778361`, a)
+	}
+
+	l := p.GeodesicPerimeter(WGS84)
+	if !almostEqual(l, 443770, 100) {
+		t.Fatalf(`perimeter was %v, should be about 443770`, l)
+	}
+}
+
+func TestGeodesicRingSign(t *testing.T) {
+	cw := []Point{{0, 0}, {0, 1}, {1, 1}, {1, 0}, {0, 0}}
+	ccw := []Point{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {0, 0}}
+
+	if a := WGS84.ringSignedArea(cw); a <= 0 {
+		t.Errorf(`clockwise ring area was %v, should be positive`, a)
+	}
+
+	if a := WGS84.ringSignedArea(ccw); a >= 0 {
+		t.Errorf(`counter-clockwise ring area was %v, should be negative`, a)
+	}
+}
+
+func TestPolygonContains(t *testing.T) {
+	p := testSquareWithHole
+	p.Box = Box{MinX: 0, MinY: 0, MaxX: 10, MaxY: 10}
+
+	tests := []struct {
+		pt       Point
+		expected bool
+	}{
+		{Point{1, 1}, true},
+		{Point{5, 5}, false}, // hole
+		{Point{7, 5}, true},
+		{Point{11, 5}, false},
+		{Point{-1, -1}, false},
+	}
+
+	for _, tt := range tests {
+		if p.Contains(tt.pt) != tt.expected {
+			t.Fatalf(`contains %v should be %v`, tt.pt, tt.expected)
+		}
+	}
+}
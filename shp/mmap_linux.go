@@ -0,0 +1,44 @@
+//go:build linux
+
+package shp
+
+import (
+	"os"
+	"syscall"
+)
+
+// Memory map .shp file for reading. Records are read as views without copying or allocating, see Mapped.
+func OpenMapped(fname string) (m *Mapped, err error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, err
+	}
+	// Mapping stays valid after closing file
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	if fi.Size() == 0 {
+		return NewMapped(nil)
+	}
+
+	data, err := syscall.Mmap(int(f.Fd()), 0, int(fi.Size()), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, &os.PathError{Op: `mmap`, Path: fname, Err: err}
+	}
+
+	m, err = NewMapped(data)
+	if err != nil {
+		_ = syscall.Munmap(data)
+		return nil, err
+	}
+
+	m.unmap = func() error {
+		return syscall.Munmap(data)
+	}
+
+	return m, nil
+}
@@ -0,0 +1,66 @@
+//go:build linux
+
+package shp
+
+import (
+	"io"
+	"testing"
+)
+
+func TestOpenMapped(t *testing.T) {
+	m, err := OpenMapped(writePolygons(t, 10, 100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	count := 0
+	for {
+		v, err := m.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		checkView(t, v)
+		count++
+	}
+
+	if count != 10 {
+		t.Fatalf(`read %d records, should be 10`, count)
+	}
+}
+
+func BenchmarkMapped(b *testing.B) {
+	fpath := writePolygons(b, benchRecords, benchPoints)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		m, err := OpenMapped(fpath)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		sum := 0.0
+		for {
+			v, err := m.Next()
+			if err == io.EOF {
+				break
+			}
+
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			for j := 0; j < v.NumPoints(); j++ {
+				sum += v.Point(j).X
+			}
+		}
+
+		m.Close()
+	}
+}
@@ -0,0 +1,135 @@
+package shp
+
+import (
+	"encoding/binary"
+	"fmt"
+	"golang.org/x/xerrors"
+	"io"
+)
+
+type PartType int32
+
+const (
+	TriangleStrip PartType = 0
+	TriangleFan   PartType = 1
+	OuterRing     PartType = 2
+	InnerRing     PartType = 3
+	FirstRing     PartType = 4
+	Ring          PartType = 5
+)
+
+func (pt PartType) String() string {
+	switch pt {
+	case TriangleStrip:
+		return "TriangleStrip"
+	case TriangleFan:
+		return "TriangleFan"
+	case OuterRing:
+		return "OuterRing"
+	case InnerRing:
+		return "InnerRing"
+	case FirstRing:
+		return "FirstRing"
+	case Ring:
+		return "Ring"
+	default:
+		return fmt.Sprintf("Unsupported part type: %d", pt)
+	}
+}
+
+type MultiPatch struct {
+	Box       Box
+	NumParts  uint32
+	NumPoints uint32
+	Parts     []uint32
+	PartTypes []PartType
+	Points    []Point
+	ZRange    [2]float64
+	ZArray    []float64
+	MRange    [2]float64
+	MArray    []float64
+}
+
+func (p MultiPatch) String() string {
+	return fmt.Sprintf(`%v parts %v points Box(%v)`, p.NumParts, p.NumPoints, p.Box)
+}
+
+func (p MultiPatch) Validate() error {
+	err := validateParts(p.NumParts, p.NumPoints, p.Parts, p.Points)
+	if err != nil {
+		return err
+	}
+
+	if len(p.PartTypes) != int(p.NumParts) {
+		return fmt.Errorf(`part types mismatch`)
+	}
+
+	err = validateArray(`Z`, p.ZArray, p.NumPoints)
+	if err != nil {
+		return err
+	}
+
+	return validateArray(`M`, p.MArray, p.NumPoints)
+}
+
+/*
+Position     Field      Value     Type    Number    Order
+Byte 0       Shape Type 31        Integer 1         Little
+Byte 4       Box        Box       Double  4         Little
+Byte 36      NumParts   NumParts  Integer 1         Little
+Byte 40      NumPoints  NumPoints Integer 1         Little
+Byte 44      Parts      Parts     Integer NumParts  Little
+Byte W       PartTypes  PartTypes Integer NumParts  Little
+Byte X       Points     Points    Point   NumPoints Little
+Byte Y       Zmin       Zmin      Double  1         Little
+Byte Y + 8   Zmax       Zmax      Double  1         Little
+Byte Y + 16  Zarray     Zarray    Double  NumPoints Little
+Byte Z*      Mmin       Mmin      Double  1         Little
+Byte Z + 8*  Mmax       Mmax      Double  1         Little
+Byte Z + 16* Marray     Marray    Double  NumPoints Little
+
+Note: W = 44 + (4 * NumParts), X = W + (4 * NumParts), Y = X + (16 * NumPoints), Z = Y + 16 + (8 * NumPoints) * optional
+*/
+func (p MultiPatch) read(r io.ReadSeeker) (ShapeTypeI, error) {
+	hdr, err := readPolyHeader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Box = hdr.Box
+	p.NumParts = hdr.NumParts
+	p.NumPoints = hdr.NumPoints
+
+	p.Parts, err = readParts(r, p.NumParts)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkCount(r, `NumParts`, p.NumParts, 4)
+	if err != nil {
+		return nil, xerrors.Errorf(`part types: %w`, err)
+	}
+
+	p.PartTypes = make([]PartType, p.NumParts)
+	err = binary.Read(r, binary.LittleEndian, &p.PartTypes)
+	if err != nil {
+		return nil, xerrors.Errorf(`part types: %w`, err)
+	}
+
+	p.Points, err = readPoints(r, p.NumPoints)
+	if err != nil {
+		return nil, err
+	}
+
+	p.ZRange, p.ZArray, err = readRange(r, p.NumPoints, `Z`, false)
+	if err != nil {
+		return nil, err
+	}
+
+	p.MRange, p.MArray, err = readRange(r, p.NumPoints, `M`, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
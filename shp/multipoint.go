@@ -0,0 +1,171 @@
+package shp
+
+import (
+	"fmt"
+	"io"
+)
+
+type MultiPoint struct {
+	Box       Box
+	NumPoints uint32
+	Points    []Point
+}
+
+func (p MultiPoint) String() string {
+	return fmt.Sprintf(`%v points Box(%v)`, p.NumPoints, p.Box)
+}
+
+func (p MultiPoint) Validate() error {
+	if len(p.Points) != int(p.NumPoints) {
+		return fmt.Errorf(`numpoints mismatch`)
+	}
+
+	return nil
+}
+
+/*
+Position Field      Value     Type    Number    Order
+Byte 0   Shape Type 8         Integer 1         Little
+Byte 4   Box        Box       Double  4         Little
+Byte 36  NumPoints  NumPoints Integer 1         Little
+Byte 40  Points     Points    Point   NumPoints Little
+*/
+func (p MultiPoint) read(r io.ReadSeeker) (ShapeTypeI, error) {
+	hdr, err := readMultiPointHeader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Box = hdr.Box
+	p.NumPoints = hdr.NumPoints
+
+	p.Points, err = readPoints(r, p.NumPoints)
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+type MultiPointM struct {
+	Box       Box
+	NumPoints uint32
+	Points    []Point
+	MRange    [2]float64
+	MArray    []float64
+}
+
+func (p MultiPointM) String() string {
+	return fmt.Sprintf(`%v points Box(%v)`, p.NumPoints, p.Box)
+}
+
+func (p MultiPointM) Validate() error {
+	if len(p.Points) != int(p.NumPoints) {
+		return fmt.Errorf(`numpoints mismatch`)
+	}
+
+	return validateArray(`M`, p.MArray, p.NumPoints)
+}
+
+/*
+Position    Field      Value     Type    Number    Order
+Byte 0      Shape Type 28        Integer 1         Little
+Byte 4      Box        Box       Double  4         Little
+Byte 36     NumPoints  NumPoints Integer 1         Little
+Byte 40     Points     Points    Point   NumPoints Little
+Byte X*     Mmin       Mmin      Double  1         Little
+Byte X+8*   Mmax       Mmax      Double  1         Little
+Byte X+16*  Marray     Marray    Double  NumPoints Little
+
+Note: X = 40 + (16 * NumPoints)
+*/
+func (p MultiPointM) read(r io.ReadSeeker) (ShapeTypeI, error) {
+	hdr, err := readMultiPointHeader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Box = hdr.Box
+	p.NumPoints = hdr.NumPoints
+
+	p.Points, err = readPoints(r, p.NumPoints)
+	if err != nil {
+		return nil, err
+	}
+
+	p.MRange, p.MArray, err = readRange(r, p.NumPoints, `M`, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+type MultiPointZ struct {
+	Box       Box
+	NumPoints uint32
+	Points    []Point
+	ZRange    [2]float64
+	ZArray    []float64
+	MRange    [2]float64
+	MArray    []float64
+}
+
+func (p MultiPointZ) String() string {
+	return fmt.Sprintf(`%v points Box(%v)`, p.NumPoints, p.Box)
+}
+
+func (p MultiPointZ) Validate() error {
+	if len(p.Points) != int(p.NumPoints) {
+		return fmt.Errorf(`numpoints mismatch`)
+	}
+
+	err := validateArray(`Z`, p.ZArray, p.NumPoints)
+	if err != nil {
+		return err
+	}
+
+	return validateArray(`M`, p.MArray, p.NumPoints)
+}
+
+/*
+Position    Field      Value     Type    Number    Order
+Byte 0      Shape Type 18        Integer 1         Little
+Byte 4      Box        Box       Double  4         Little
+Byte 36     NumPoints  NumPoints Integer 1         Little
+Byte 40     Points     Points    Point   NumPoints Little
+Byte X      Zmin       Zmin      Double  1         Little
+Byte X+8    Zmax       Zmax      Double  1         Little
+Byte X+16   Zarray     Zarray    Double  NumPoints Little
+Byte Y*     Mmin       Mmin      Double  1         Little
+Byte Y+8*   Mmax       Mmax      Double  1         Little
+Byte Y+16*  Marray     Marray    Double  NumPoints Little
+
+Note: X = 40 + (16 * NumPoints), Y = X + 16 + (8 * NumPoints)
+*/
+func (p MultiPointZ) read(r io.ReadSeeker) (ShapeTypeI, error) {
+	hdr, err := readMultiPointHeader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Box = hdr.Box
+	p.NumPoints = hdr.NumPoints
+
+	p.Points, err = readPoints(r, p.NumPoints)
+	if err != nil {
+		return nil, err
+	}
+
+	p.ZRange, p.ZArray, err = readRange(r, p.NumPoints, `Z`, false)
+	if err != nil {
+		return nil, err
+	}
+
+	p.MRange, p.MArray, err = readRange(r, p.NumPoints, `M`, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
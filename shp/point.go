@@ -0,0 +1,110 @@
+package shp
+
+import (
+	"encoding/binary"
+	"fmt"
+	"golang.org/x/xerrors"
+	"io"
+)
+
+// Null shape, record without geometry
+type Null struct{}
+
+func (n Null) String() string {
+	return `Null`
+}
+
+func (n Null) Validate() error {
+	return nil
+}
+
+func (n Null) read(r io.ReadSeeker) (ShapeTypeI, error) {
+	return n, nil
+}
+
+func (p Point) Validate() error {
+	return nil
+}
+
+/*
+Position Field      Value Type    Number Order
+Byte 0   Shape Type 1     Integer 1      Little
+Byte 4   X          X     Double  1      Little
+Byte 12  Y          Y     Double  1      Little
+*/
+func (p Point) read(r io.ReadSeeker) (ShapeTypeI, error) {
+	err := binary.Read(r, binary.LittleEndian, &p)
+	if err != nil {
+		return nil, xerrors.Errorf(`couldn't read point: %w`, err)
+	}
+
+	return p, nil
+}
+
+type PointM struct {
+	X, Y float64
+	M    float64
+}
+
+func (p PointM) String() string {
+	return fmt.Sprintf(`%v x %v M:%v`, p.X, p.Y, p.M)
+}
+
+func (p PointM) Validate() error {
+	return nil
+}
+
+/*
+Position Field      Value Type    Number Order
+Byte 0   Shape Type 21    Integer 1      Little
+Byte 4   X          X     Double  1      Little
+Byte 12  Y          Y     Double  1      Little
+Byte 20  M          M     Double  1      Little
+*/
+func (p PointM) read(r io.ReadSeeker) (ShapeTypeI, error) {
+	err := binary.Read(r, binary.LittleEndian, &p)
+	if err != nil {
+		return nil, xerrors.Errorf(`couldn't read point: %w`, err)
+	}
+
+	return p, nil
+}
+
+type PointZ struct {
+	X, Y float64
+	Z    float64
+	M    float64
+}
+
+func (p PointZ) String() string {
+	return fmt.Sprintf(`%v x %v Z:%v M:%v`, p.X, p.Y, p.Z, p.M)
+}
+
+func (p PointZ) Validate() error {
+	return nil
+}
+
+/*
+Position Field      Value Type    Number Order
+Byte 0   Shape Type 11    Integer 1      Little
+Byte 4   X          X     Double  1      Little
+Byte 12  Y          Y     Double  1      Little
+Byte 20  Z          Z     Double  1      Little
+Byte 28  M          M     Double  1      Little   (optional)
+*/
+func (p PointZ) read(r io.ReadSeeker) (ShapeTypeI, error) {
+	var xyz [3]float64
+	err := binary.Read(r, binary.LittleEndian, &xyz)
+	if err != nil {
+		return nil, xerrors.Errorf(`couldn't read point: %w`, err)
+	}
+
+	p.X, p.Y, p.Z = xyz[0], xyz[1], xyz[2]
+
+	err = binary.Read(r, binary.LittleEndian, &p.M)
+	if err != nil && err != io.EOF {
+		return nil, xerrors.Errorf(`couldn't read point M: %w`, err)
+	}
+
+	return p, nil
+}
@@ -0,0 +1,202 @@
+package shp
+
+import (
+	"fmt"
+	"io"
+)
+
+// Polygon has one or more rings. Outer rings are clockwise and holes are counter-clockwise.
+type Polygon struct {
+	Box       Box
+	NumParts  uint32
+	NumPoints uint32
+	Parts     []uint32
+	Points    []Point
+}
+
+func (p Polygon) String() string {
+	return fmt.Sprintf(`%v parts %v points Box(%v)`, p.NumParts, p.NumPoints, p.Box)
+}
+
+func (p Polygon) Validate() error {
+	return validateParts(p.NumParts, p.NumPoints, p.Parts, p.Points)
+}
+
+/*
+Position Field      Value     Type    Number    Order
+Byte 0   Shape Type 5         Integer 1         Little
+Byte 4   Box        Box       Double  4         Little
+Byte 36  NumParts   NumParts  Integer 1         Little
+Byte 40  NumPoints  NumPoints Integer 1         Little
+Byte 44  Parts      Parts     Integer NumParts  Little
+Byte X   Points     Points    Point   NumPoints Little
+
+Note: X = 44 + 4 * NumParts
+*/
+func (p Polygon) read(r io.ReadSeeker) (ShapeTypeI, error) {
+	hdr, err := readPolyHeader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Box = hdr.Box
+	p.NumParts = hdr.NumParts
+	p.NumPoints = hdr.NumPoints
+
+	p.Parts, err = readParts(r, p.NumParts)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Points, err = readPoints(r, p.NumPoints)
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+type PolygonM struct {
+	Box       Box
+	NumParts  uint32
+	NumPoints uint32
+	Parts     []uint32
+	Points    []Point
+	MRange    [2]float64
+	MArray    []float64
+}
+
+func (p PolygonM) String() string {
+	return fmt.Sprintf(`%v parts %v points Box(%v)`, p.NumParts, p.NumPoints, p.Box)
+}
+
+func (p PolygonM) Validate() error {
+	err := validateParts(p.NumParts, p.NumPoints, p.Parts, p.Points)
+	if err != nil {
+		return err
+	}
+
+	return validateArray(`M`, p.MArray, p.NumPoints)
+}
+
+/*
+Position     Field      Value     Type    Number    Order
+Byte 0       Shape Type 25        Integer 1         Little
+Byte 4       Box        Box       Double  4         Little
+Byte 36      NumParts   NumParts  Integer 1         Little
+Byte 40      NumPoints  NumPoints Integer 1         Little
+Byte 44      Parts      Parts     Integer NumParts  Little
+Byte X       Points     Points    Point   NumPoints Little
+Byte Y*      Mmin       Mmin      Double  1         Little
+Byte Y + 8*  Mmax       Mmax      Double  1         Little
+Byte Y + 16* Marray     Marray    Double  NumPoints Little
+
+Note: X = 44 + (4 * NumParts), Y = X + (16 * NumPoints) * optional
+*/
+func (p PolygonM) read(r io.ReadSeeker) (ShapeTypeI, error) {
+	hdr, err := readPolyHeader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Box = hdr.Box
+	p.NumParts = hdr.NumParts
+	p.NumPoints = hdr.NumPoints
+
+	p.Parts, err = readParts(r, p.NumParts)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Points, err = readPoints(r, p.NumPoints)
+	if err != nil {
+		return nil, err
+	}
+
+	p.MRange, p.MArray, err = readRange(r, p.NumPoints, `M`, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+type PolygonZ struct {
+	Box       Box
+	NumParts  uint32
+	NumPoints uint32
+	Parts     []uint32
+	Points    []Point
+	ZRange    [2]float64
+	ZArray    []float64
+	MRange    [2]float64
+	MArray    []float64
+}
+
+func (p PolygonZ) String() string {
+	return fmt.Sprintf(`%v parts %v points Box(%v)`, p.NumParts, p.NumPoints, p.Box)
+}
+
+func (p PolygonZ) Validate() error {
+	err := validateParts(p.NumParts, p.NumPoints, p.Parts, p.Points)
+	if err != nil {
+		return err
+	}
+
+	err = validateArray(`Z`, p.ZArray, p.NumPoints)
+	if err != nil {
+		return err
+	}
+
+	return validateArray(`M`, p.MArray, p.NumPoints)
+}
+
+/*
+Position     Field      Value     Type    Number    Order
+Byte 0       Shape Type 15        Integer 1         Little
+Byte 4       Box        Box       Double  4         Little
+Byte 36      NumParts   NumParts  Integer 1         Little
+Byte 40      NumPoints  NumPoints Integer 1         Little
+Byte 44      Parts      Parts     Integer NumParts  Little
+Byte X       Points     Points    Point   NumPoints Little
+Byte Y       Zmin       Zmin      Double  1         Little
+Byte Y + 8   Zmax       Zmax      Double  1         Little
+Byte Y + 16  Zarray     Zarray    Double  NumPoints Little
+Byte Z*      Mmin       Mmin      Double  1         Little
+Byte Z + 8*  Mmax       Mmax      Double  1         Little
+Byte Z + 16* Marray     Marray    Double  NumPoints Little
+
+Note: X = 44 + (4 * NumParts), Y = X + (16 * NumPoints), Z = Y + 16 + (8 * NumPoints) * optional
+*/
+func (p PolygonZ) read(r io.ReadSeeker) (ShapeTypeI, error) {
+	hdr, err := readPolyHeader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Box = hdr.Box
+	p.NumParts = hdr.NumParts
+	p.NumPoints = hdr.NumPoints
+
+	p.Parts, err = readParts(r, p.NumParts)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Points, err = readPoints(r, p.NumPoints)
+	if err != nil {
+		return nil, err
+	}
+
+	p.ZRange, p.ZArray, err = readRange(r, p.NumPoints, `Z`, false)
+	if err != nil {
+		return nil, err
+	}
+
+	p.MRange, p.MArray, err = readRange(r, p.NumPoints, `M`, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
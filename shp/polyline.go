@@ -0,0 +1,121 @@
+package shp
+
+import (
+	"fmt"
+	"io"
+)
+
+type PolyLine struct {
+	Box       Box
+	NumParts  uint32
+	NumPoints uint32
+	Parts     []uint32
+	Points    []Point
+}
+
+func (p PolyLine) String() string {
+	return fmt.Sprintf(`%v parts %v points Box(%v)`, p.NumParts, p.NumPoints, p.Box)
+}
+
+func (p PolyLine) Validate() error {
+	return validateParts(p.NumParts, p.NumPoints, p.Parts, p.Points)
+}
+
+/*
+Position Field      Value     Type    Number    Order
+Byte 0   Shape Type 3         Integer 1         Little
+Byte 4   Box        Box       Double  4         Little
+Byte 36  NumParts   NumParts  Integer 1         Little
+Byte 40  NumPoints  NumPoints Integer 1         Little
+Byte 44  Parts      Parts     Integer NumParts  Little
+Byte X   Points     Points    Point   NumPoints Little
+
+Note: X = 44 + 4 * NumParts
+*/
+func (p PolyLine) read(r io.ReadSeeker) (ShapeTypeI, error) {
+	hdr, err := readPolyHeader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Box = hdr.Box
+	p.NumParts = hdr.NumParts
+	p.NumPoints = hdr.NumPoints
+
+	p.Parts, err = readParts(r, p.NumParts)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Points, err = readPoints(r, p.NumPoints)
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+type PolyLineM struct {
+	Box       Box
+	NumParts  uint32
+	NumPoints uint32
+	Parts     []uint32
+	Points    []Point
+	MRange    [2]float64
+	MArray    []float64
+}
+
+func (p PolyLineM) String() string {
+	return fmt.Sprintf(`%v parts %v points Box(%v)`, p.NumParts, p.NumPoints, p.Box)
+}
+
+func (p PolyLineM) Validate() error {
+	err := validateParts(p.NumParts, p.NumPoints, p.Parts, p.Points)
+	if err != nil {
+		return err
+	}
+
+	return validateArray(`M`, p.MArray, p.NumPoints)
+}
+
+/*
+Position     Field      Value     Type    Number    Order
+Byte 0       Shape Type 23        Integer 1         Little
+Byte 4       Box        Box       Double  4         Little
+Byte 36      NumParts   NumParts  Integer 1         Little
+Byte 40      NumPoints  NumPoints Integer 1         Little
+Byte 44      Parts      Parts     Integer NumParts  Little
+Byte X       Points     Points    Point   NumPoints Little
+Byte Y*      Mmin       Mmin      Double  1         Little
+Byte Y + 8*  Mmax       Mmax      Double  1         Little
+Byte Y + 16* Marray     Marray    Double  NumPoints Little
+
+Note: X = 44 + (4 * NumParts), Y = X + (16 * NumPoints) * optional
+*/
+func (p PolyLineM) read(r io.ReadSeeker) (ShapeTypeI, error) {
+	hdr, err := readPolyHeader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Box = hdr.Box
+	p.NumParts = hdr.NumParts
+	p.NumPoints = hdr.NumPoints
+
+	p.Parts, err = readParts(r, p.NumParts)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Points, err = readPoints(r, p.NumPoints)
+	if err != nil {
+		return nil, err
+	}
+
+	p.MRange, p.MArray, err = readRange(r, p.NumPoints, `M`, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
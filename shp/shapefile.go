@@ -6,30 +6,64 @@ import (
 	"fmt"
 	"github.com/raspi/GeoESRIShapeFile/common"
 	"io"
-	"log"
+	"math"
 )
 
+// Default maximum record content length, see SetMaxRecordSize. Enough for over 4 million PolygonZ points.
+const DefaultMaxRecordSize = 128 * 1024 * 1024
+
 type RecordHeader struct {
 	Number uint32
 	Length uint32
 }
 
 type ShapeFile struct {
+	Header1     common.ShapeFileHeader1 // File code and length
+	Header2     common.ShapeFileHeader2 // Shape type and bounding box
 	r           common.ReadSeekCloser
-	debug       bool
+	logger      common.Logger // nil for no logging
 	initialized bool
+	filter      *Box   // Spatial filter for ReadRecord
+	size        int64  // File size, records can't be longer than what is left
+	maxRecord   uint32 // Maximum record content length, see SetMaxRecordSize
+
+	// Lenient mode, see SetLenient
+	lenient      bool
+	index        RecordIndex
+	recordErrors []*RecordError
+	next         uint32 // 0-based number of next record read by ReadRecord
+	length       int64  // Content length of last record header read, -1 if header was invalid
+
+	iterErr error              // Error which stopped Shapes iterator, see Err
+	hdr     [8]byte            // Record header buffer, binary.Read would allocate
+	prefix  [recordBoxEnd]byte // Shape type and box read first when box filter is set
 }
 
 func (sf *ShapeFile) Close() error {
+	if sf == nil || sf.r == nil {
+		// Not opened
+		return nil
+	}
+
 	return sf.r.Close()
 }
 
+// Set logger, for example *slog.Logger. nil disables logging, which is the default.
+func (sf *ShapeFile) SetLogger(l common.Logger) {
+	sf.logger = l
+}
+
+func (sf ShapeFile) GetLogger() common.Logger {
+	return sf.logger
+}
+
+// Deprecated: use SetLogger. true logs debug messages with standard log package.
 func (sf *ShapeFile) SetDebug(flag bool) {
-	sf.debug = flag
+	sf.logger = common.DebugLogger(flag)
 }
 
 func (sf ShapeFile) GetDebug() bool {
-	return sf.debug
+	return sf.logger != nil
 }
 
 func (sf *ShapeFile) ReadRecordAt(offset int64) (idx uint32, record ShapeTypeI, err error) {
@@ -38,56 +72,390 @@ func (sf *ShapeFile) ReadRecordAt(offset int64) (idx uint32, record ShapeTypeI,
 		return 0, nil, err
 	}
 
-	return sf.ReadRecord()
+	// Caller asked for this exact record, so box filter is not used
+	idx, err = sf.readRecord(nil, func(content []byte) (err error) {
+		record, err = DecodeRecord(content)
+		return err
+	})
+	if err != nil {
+		return idx, nil, err
+	}
+
+	// ReadRecord continues from here
+	sf.next = idx + 1
+
+	return idx, record, nil
+}
+
+/*
+Read record at offset with content length taken from .shx entry. Header and content are read
+with one ReadAt call if file implements io.ReaderAt, which is one ranged read for files opened
+with NewReaderAt. Box filter is not used. ReadRecord continues from next record.
+*/
+func (sf *ShapeFile) ReadIndexedRecord(offset int64, length int64) (idx uint32, record ShapeTypeI, err error) {
+	if !sf.initialized {
+		return 0, nil, common.ErrorNotInitialized
+	}
+
+	if length < 0 || length > int64(sf.GetMaxRecordSize()) {
+		return 0, nil, &RecordTooLarge{Length: length, Max: sf.GetMaxRecordSize()}
+	}
+
+	if sf.size > 0 && length > sf.size-offset-8 {
+		return 0, nil, &RecordPastEOF{Length: length, Remaining: sf.size - offset - 8}
+	}
+
+	buf := getBuffer(8 + int(length))
+	defer putBuffer(buf)
+	data := *buf
+
+	if ra, ok := sf.r.(io.ReaderAt); ok {
+		n, err := ra.ReadAt(data, offset)
+		if n != len(data) {
+			if err == nil || err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+
+			return 0, nil, err
+		}
+	} else {
+		_, err = sf.r.Seek(offset, io.SeekStart)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		_, err = io.ReadFull(sf.r, data)
+		if err != nil {
+			return 0, nil, err
+		}
+	}
+
+	// ReadRecord continues from here
+	_, err = sf.r.Seek(offset+int64(len(data)), io.SeekStart)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	number := binary.BigEndian.Uint32(data[0:])
+	if number == 0 {
+		return 0, nil, fmt.Errorf(`record at offset %d has number 0, numbers start from 1`, offset)
+	}
+
+	if hdrLength := common.WordsToBytes(data[4:]); hdrLength != length {
+		return 0, nil, fmt.Errorf(`record at offset %d has length %d, index has %d`, offset, hdrLength, length)
+	}
+
+	if sf.logger != nil {
+		sf.logger.Debug(`read shape`, `number`, number-1, `length`, length, `offset`, offset)
+	}
+
+	record, err = DecodeRecord(data[8:])
+	if err != nil {
+		return 0, nil, err
+	}
+
+	sf.next = number
+
+	return number - 1, record, nil
 }
 
+// Read next record. If box filter is set, records not intersecting it are skipped.
+// In lenient mode corrupt records are skipped, see SetLenient.
 func (sf *ShapeFile) ReadRecord() (idx uint32, record ShapeTypeI, err error) {
+	idx, err = sf.nextRecord(func(content []byte) (err error) {
+		record, err = DecodeRecord(content)
+		return err
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return idx, record, nil
+}
+
+// Same as ReadRecord, but record is decoded into g reusing its slices, see Geometry
+func (sf *ShapeFile) ReadRecordInto(g *Geometry) (idx uint32, err error) {
+	return sf.nextRecord(func(content []byte) error {
+		return DecodeInto(content, g)
+	})
+}
+
+// Read next record with box filter and lenient mode, decode is called with record content
+func (sf *ShapeFile) nextRecord(decode func(content []byte) error) (idx uint32, err error) {
+	for {
+		offset := int64(-1)
+		if sf.lenient && sf.initialized {
+			offset, err = sf.r.Seek(0, io.SeekCurrent)
+			if err != nil {
+				return 0, err
+			}
+		}
+
+		number := sf.next
+		idx, err = sf.readRecord(sf.filter, decode)
+		sf.next++
+
+		if err == errFiltered {
+			continue
+		}
+
+		if err != nil && err != io.EOF && offset >= 0 {
+			err = sf.skipRecord(number, offset, sf.length, err)
+			if err != nil {
+				return 0, err
+			}
+
+			continue
+		}
+
+		return idx, err
+	}
+}
+
+// Set spatial filter for ReadRecord. Only record header and bounding box is read for records
+// which do not intersect given box. Use nil to remove filter.
+func (sf *ShapeFile) SetBoxFilter(b *Box) {
+	if b == nil {
+		sf.filter = nil
+		return
+	}
+
+	filter := *b
+	sf.filter = &filter
+}
+
+func (sf *ShapeFile) GetBoxFilter() *Box {
+	return sf.filter
+}
+
+// Read record at current position into pooled buffer and call decode with its content. Buffer is
+// reused after decode returns.
+func (sf *ShapeFile) readRecord(filter *Box, decode func(content []byte) error) (idx uint32, err error) {
 	if !sf.initialized {
-		return idx, nil, common.ErrorNotInitialized
+		return idx, common.ErrorNotInitialized
 	}
 
 	offset := int64(-1)
-	if sf.debug {
+	if sf.logger != nil {
 		offset, err = sf.r.Seek(0, io.SeekCurrent)
 		if err != nil {
-			return 0, nil, err
+			return 0, err
 		}
 	}
 
-	var rechdr RecordHeader
-	err = binary.Read(sf.r, binary.BigEndian, &rechdr)
+	sf.length = -1
+
+	_, err = io.ReadFull(sf.r, sf.hdr[:])
+	if err != nil {
+		return 0, err
+	}
+
+	number := binary.BigEndian.Uint32(sf.hdr[0:])
+	length := common.WordsToBytes(sf.hdr[4:])
+
+	err = sf.checkRecordLength(number, length)
 	if err != nil {
-		return 0, nil, err
+		return 0, err
 	}
 
-	rechdr.Length *= 2
+	// Lenient mode continues right after record if header looks valid, see skipRecord
+	if length >= 4 && number == sf.next+1 {
+		sf.length = length
+	}
+
+	// Length is at most maximum record size, so it fits
+	rechdr := RecordHeader{Number: number, Length: uint32(length)}
+
 	rechdr.Number--
 
-	rawshapedata := make([]byte, rechdr.Length)
-	rBytes, err := sf.r.Read(rawshapedata)
+	read := 0
+
+	if filter != nil {
+		// Read shape type and bounding box (bytes 0-36 of record content) first, so that nothing
+		// is allocated for skipped records
+		read = recordBoxEnd
+		if rechdr.Length < recordBoxEnd {
+			read = int(rechdr.Length)
+		}
+
+		_, err = io.ReadFull(sf.r, sf.prefix[:read])
+		if err != nil {
+			return 0, inRecord(err)
+		}
+
+		if box, ok := recordBox(sf.prefix[:read]); ok && !box.Intersects(*filter) {
+			_, err = sf.r.Seek(int64(rechdr.Length)-int64(read), io.SeekCurrent)
+			if err != nil {
+				return 0, err
+			}
+
+			if sf.logger != nil {
+				sf.logger.Debug(`skipped shape`, `number`, rechdr.Number, `length`, rechdr.Length, `offset`, offset, `box`, box)
+			}
+
+			return rechdr.Number, errFiltered
+		}
+	}
+
+	buf := getBuffer(int(rechdr.Length))
+	defer putBuffer(buf)
+	rawshapedata := *buf
+	copy(rawshapedata, sf.prefix[:read])
+
+	_, err = io.ReadFull(sf.r, rawshapedata[read:])
 	if err != nil {
-		return 0, nil, err
+		return 0, inRecord(err)
+	}
+
+	if sf.logger != nil {
+		sf.logger.Debug(`read shape`, `number`, rechdr.Number, `length`, rechdr.Length, `offset`, offset)
 	}
 
-	if uint32(rBytes) != rechdr.Length {
-		return 0, nil, fmt.Errorf(`read %v but len is %v?`, rBytes, rechdr.Length)
+	err = decode(rawshapedata)
+	if err != nil {
+		return 0, err
+	}
+
+	return rechdr.Number, nil
+}
+
+// Set maximum record content length in bytes. Longer records fail with RecordTooLarge before
+// anything is allocated for them. 0 sets DefaultMaxRecordSize.
+func (sf *ShapeFile) SetMaxRecordSize(max uint32) {
+	sf.maxRecord = max
+}
+
+func (sf ShapeFile) GetMaxRecordSize() uint32 {
+	if sf.maxRecord == 0 {
+		return DefaultMaxRecordSize
+	}
+
+	return sf.maxRecord
+}
+
+// Check record content length (in bytes) from record header against maximum and file size.
+// Must be called right after reading record header.
+func (sf *ShapeFile) checkRecordLength(number uint32, length int64) error {
+	if length > int64(sf.GetMaxRecordSize()) {
+		return &RecordTooLarge{Number: number, Length: length, Max: sf.GetMaxRecordSize()}
 	}
 
-	if sf.debug {
-		log.Printf(`Read shape #%[1]v with len 0x%04[2]x (%06[2]d) at offset 0x%04[3]x (%06[3]d)`, rechdr.Number, rechdr.Length, offset)
+	if sf.size <= 0 {
+		// Size is not known
+		return nil
 	}
 
-	rec, err := sf.readRecordData(bytes.NewReader(rawshapedata))
+	offset, err := sf.r.Seek(0, io.SeekCurrent)
 	if err != nil {
-		return 0, nil, err
+		return err
+	}
+
+	if length > sf.size-offset {
+		return &RecordPastEOF{Number: number, Length: length, Remaining: sf.size - offset}
+	}
+
+	return nil
+}
+
+// Raw record for debugging, see ReadRawRecord
+type RawRecord struct {
+	Offset    int64        // Offset of record header in .shp
+	Header    RecordHeader // Number is 1-based as in file, Length is in bytes, math.MaxUint32 if it doesn't fit
+	RawHeader []byte       // Record header as in file, shorter than 8 bytes if file ends inside it
+	Content   []byte       // Record content after header, shorter than Header.Length if file ends inside it
+}
+
+/*
+Read next record without decoding it. Use DecodeRecord to decode content.
+
+If record length is invalid, Header is still set but Content is nil. If file ends inside record,
+RawHeader and Content have bytes read until end of file.
+*/
+func (sf *ShapeFile) ReadRawRecord() (rec RawRecord, err error) {
+	if !sf.initialized {
+		return rec, common.ErrorNotInitialized
+	}
+
+	rec.Offset, err = sf.r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return rec, err
+	}
+
+	n, err := io.ReadFull(sf.r, sf.hdr[:])
+	if n > 0 {
+		rec.RawHeader = append([]byte{}, sf.hdr[:n]...)
+	}
+
+	if err != nil {
+		return rec, err
+	}
+
+	number := binary.BigEndian.Uint32(sf.hdr[0:])
+	length := common.WordsToBytes(sf.hdr[4:])
+
+	rec.Header = RecordHeader{Number: number, Length: math.MaxUint32}
+	if length < math.MaxUint32 {
+		rec.Header.Length = uint32(length)
+	}
+
+	err = sf.checkRecordLength(number, length)
+	if err != nil {
+		return rec, err
+	}
+
+	rec.Content = make([]byte, rec.Header.Length)
+	n, err = io.ReadFull(sf.r, rec.Content)
+	if err != nil {
+		rec.Content = rec.Content[:n]
+		return rec, inRecord(err)
+	}
+
+	return rec, nil
+}
+
+func (sf *ShapeFile) ReadRawRecordAt(offset int64) (rec RawRecord, err error) {
+	_, err = sf.r.Seek(offset, io.SeekStart)
+	if err != nil {
+		return rec, err
+	}
+
+	return sf.ReadRawRecord()
+}
+
+// Decode and validate record content (without record header)
+func DecodeRecord(content []byte) (rec ShapeTypeI, err error) {
+	rec, err = ParseRecord(content)
+	if err != nil {
+		return nil, err
 	}
 
 	err = rec.Validate()
 	if err != nil {
-		return 0, nil, err
+		return nil, err
 	}
 
-	return rechdr.Number, rec, nil
+	return rec, nil
+}
+
+// Decode record content (without record header) without validating it. Decoded counts, parts
+// and arrays may be inconsistent, see Validate().
+func ParseRecord(content []byte) (rec ShapeTypeI, err error) {
+	rec, err = (&ShapeFile{}).readRecordData(bytes.NewReader(content))
+	if err != nil {
+		return nil, inRecord(err)
+	}
+
+	return rec, nil
+}
+
+// io.EOF means end of file only between records. Inside record it's a truncated record, which
+// must not stop reading silently.
+func inRecord(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+
+	return err
 }
 
 func (sf *ShapeFile) readRecordData(r io.ReadSeeker) (rec ShapeTypeI, err error) {
@@ -100,36 +468,92 @@ func (sf *ShapeFile) readRecordData(r io.ReadSeeker) (rec ShapeTypeI, err error)
 	//log.Printf(`got shape %v`, shapeType)
 
 	switch shapeType {
+	case common.NULL:
+		return Null{}.read(r)
+	case common.POINT:
+		return Point{}.read(r)
+	case common.POLYLINE:
+		return PolyLine{}.read(r)
+	case common.POLYGON:
+		return Polygon{}.read(r)
+	case common.MULTIPOINT:
+		return MultiPoint{}.read(r)
+	case common.POINTZ:
+		return PointZ{}.read(r)
 	case common.POLYLINEZ:
-		var nrec PolyLineZ
-		return nrec.read(r)
+		return PolyLineZ{}.read(r)
+	case common.POLYGONZ:
+		return PolygonZ{}.read(r)
+	case common.MULTIPOINTZ:
+		return MultiPointZ{}.read(r)
+	case common.POINTM:
+		return PointM{}.read(r)
+	case common.POLYLINEM:
+		return PolyLineM{}.read(r)
+	case common.POLYGONM:
+		return PolygonM{}.read(r)
+	case common.MULTIPOINTM:
+		return MultiPointM{}.read(r)
+	case common.MULTIPATCH:
+		return MultiPatch{}.read(r)
 	default:
 		return nil, fmt.Errorf(`unknown shape style: %v`, shapeType)
 	}
 }
 
 func New(fname string) (sf ShapeFile, err error) {
-	sf.debug = false
-
 	f, err := common.OpenFile(fname)
 	if err != nil {
 		return sf, err
 	}
 
+	return NewFrom(f), nil
+}
+
+// Read from already opened file, for example from afero.Fs or fs.FS. Close closes r.
+func NewFrom(r common.ReadSeekCloser) ShapeFile {
 	return ShapeFile{
-		r:           f,
+		r:           r,
 		initialized: false,
-	}, nil
+	}
+}
+
+/*
+Read r in one pass, for example stdin or HTTP body. Only ReadRecord and ReadRawRecord work, reading
+at offset fails with common.ErrorNotSeekable. File size is not known, so record lengths are only
+checked against maximum record size. In lenient mode index must be given.
+*/
+func NewStream(r io.Reader) ShapeFile {
+	return NewFrom(common.NewStream(r))
+}
+
+// Read from r of given size, for example from object storage with ranged reads. See ReadIndexedRecord.
+func NewReaderAt(r io.ReaderAt, size int64) ShapeFile {
+	return NewFrom(common.NewReaderAt(r, size))
 }
 
 func (sf *ShapeFile) Initialize() (err error) {
-	err = common.ReadHeaders(sf.r)
+	sf.size, err = sf.r.Seek(0, io.SeekEnd)
+	switch {
+	case err == common.ErrorNotSeekable:
+		// Stream, size is not known
+		sf.size = 0
+	case err != nil:
+		return err
+	default:
+		_, err = sf.r.Seek(0, io.SeekStart)
+		if err != nil {
+			return err
+		}
+	}
+
+	sf.Header1, sf.Header2, err = common.ParseHeaders(sf.r)
 	if err != nil {
 		return err
 	}
 
-	if sf.debug {
-		log.Printf(`header read successfully`)
+	if sf.logger != nil {
+		sf.logger.Debug(`header read successfully`, `shape_type`, sf.Header2.ShapeType, `length`, int64(sf.Header1.Length)*2)
 	}
 
 	sf.initialized = true
@@ -1,9 +1,7 @@
 package shp
 
 import (
-	"encoding/binary"
 	"fmt"
-	"golang.org/x/xerrors"
 	"io"
 )
 
@@ -45,15 +43,17 @@ func (p PolyLineZ) String() string {
 }
 
 func (z PolyLineZ) Validate() error {
-	if len(z.Points) != int(z.NumPoints) {
-		return fmt.Errorf(`numpoints mismatch`)
+	err := validateParts(z.NumParts, z.NumPoints, z.Parts, z.Points)
+	if err != nil {
+		return err
 	}
 
-	if len(z.Parts) != int(z.NumParts) {
-		return fmt.Errorf(`numparts mismatch`)
+	err = validateArray(`Z`, z.ZArray, z.NumPoints)
+	if err != nil {
+		return err
 	}
 
-	return nil
+	return validateArray(`M`, z.MArray, z.NumPoints)
 }
 
 /*
@@ -74,52 +74,33 @@ func (z PolyLineZ) Validate() error {
 	Note:  X = 44 + (4 * NumParts), Y = X + (16 * NumPoints), Z = Y + 16 + (8 * NumPoints)*  optional
 */
 func (z PolyLineZ) read(r io.ReadSeeker) (retrec ShapeTypeI, err error) {
-	var rawrec struct {
-		Box       Box
-		NumParts  uint32
-		NumPoints uint32
-	}
-	err = binary.Read(r, binary.LittleEndian, &rawrec)
+	hdr, err := readPolyHeader(r)
 	if err != nil {
-		return nil, xerrors.Errorf(`couldn't read raw poly line header: %w`, err)
+		return nil, err
 	}
 
-	z.Box = rawrec.Box
-	z.NumParts = rawrec.NumParts
-	z.NumPoints = rawrec.NumPoints
-
-	z.Parts = make([]uint32, z.NumParts)
-	err = binary.Read(r, binary.LittleEndian, &z.Parts)
-	if err != nil {
-		return nil, xerrors.Errorf(`parts: %w`, err)
-	}
-
-	z.Points = make([]Point, z.NumPoints)
-	err = binary.Read(r, binary.LittleEndian, &z.Points)
-	if err != nil {
-		return nil, xerrors.Errorf(`points: %w`, err)
-	}
+	z.Box = hdr.Box
+	z.NumParts = hdr.NumParts
+	z.NumPoints = hdr.NumPoints
 
-	err = binary.Read(r, binary.LittleEndian, &z.ZRange)
+	z.Parts, err = readParts(r, z.NumParts)
 	if err != nil {
-		return nil, xerrors.Errorf(`zrange: %w`, err)
+		return nil, err
 	}
 
-	z.ZArray = make([]float64, z.NumPoints)
-	err = binary.Read(r, binary.LittleEndian, &z.ZArray)
+	z.Points, err = readPoints(r, z.NumPoints)
 	if err != nil {
-		return nil, xerrors.Errorf(`Z-Array: %w`, err)
+		return nil, err
 	}
 
-	err = binary.Read(r, binary.LittleEndian, &z.MRange)
+	z.ZRange, z.ZArray, err = readRange(r, z.NumPoints, `Z`, false)
 	if err != nil {
-		return nil, xerrors.Errorf(`M Range: %w`, err)
+		return nil, err
 	}
 
-	z.MArray = make([]float64, z.NumPoints)
-	err = binary.Read(r, binary.LittleEndian, &z.MArray)
+	z.MRange, z.MArray, err = readRange(r, z.NumPoints, `M`, true)
 	if err != nil {
-		return nil, xerrors.Errorf(`M-Array: %w`, err)
+		return nil, err
 	}
 
 	return z, nil
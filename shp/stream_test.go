@@ -0,0 +1,60 @@
+package shp
+
+import (
+	"bytes"
+	"github.com/raspi/GeoESRIShapeFile/common"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"testing"
+	"testing/iotest"
+)
+
+func readAllRecords(t *testing.T, sf ShapeFile) (records []ShapeTypeI) {
+	err := sf.Initialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for {
+		_, rec, err := sf.ReadRecord()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		records = append(records, rec)
+	}
+
+	return records
+}
+
+func TestNewStream(t *testing.T) {
+	data, err := ioutil.ReadFile(`../_test_files/polygonz.shp`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := common.NewReadSeekCloser(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := readAllRecords(t, NewFrom(f))
+
+	// Pipes and network connections return partial reads
+	sf := NewStream(iotest.OneByteReader(bytes.NewReader(data)))
+	got := readAllRecords(t, sf)
+
+	if len(want) == 0 || !reflect.DeepEqual(got, want) {
+		t.Fatalf(`streamed %v, should be %v`, got, want)
+	}
+
+	_, _, err = sf.ReadRecordAt(100)
+	if err != common.ErrorNotSeekable {
+		t.Fatalf(`expected ErrorNotSeekable, got %v`, err)
+	}
+}
@@ -0,0 +1,302 @@
+package shp
+
+import (
+	"encoding/binary"
+	"fmt"
+	"github.com/raspi/GeoESRIShapeFile/common"
+	"io"
+	"math"
+)
+
+/*
+RecordView is a record which is decoded lazily from underlying bytes, see Mapped. Counts are checked
+against record length when view is created, so accessors don't fail. Views are only valid until
+the Mapped they came from is closed.
+
+Offsets of arrays relative to start of record content:
+
+	Point types:          X at 4, Y at 12, Z or M at 20, M of PointZ at 28
+	Multi point types:    Points at 40, then Z range and Z array, then M range and M array
+	Poly line, polygon:   Parts at 44, Points after parts, then Z and M as above
+	Multi patch:          Parts at 44, PartTypes after parts, Points after part types, then Z and M
+*/
+type RecordView struct {
+	Number  uint32 // 0-based, same as returned by ReadRecord
+	Offset  int64  // Offset of record header in .shp
+	Content []byte // Record content after header
+
+	shapeType common.ShapeType
+	numParts  int
+	numPoints int
+	partsAt   int
+	typesAt   int // Multi patch part types, 0 for others
+	pointsAt  int
+	zAt       int // Start of Z array, 0 if none
+	mAt       int // Start of M array, 0 if none
+}
+
+// Create view over record content. Counts are checked so that arrays fit in content.
+func NewRecordView(number uint32, offset int64, content []byte) (v RecordView, err error) {
+	v = RecordView{Number: number, Offset: offset, Content: content}
+
+	if len(content) < 4 {
+		return v, fmt.Errorf(`record content is %d bytes, shape type doesn't fit`, len(content))
+	}
+
+	v.shapeType = common.ShapeType(binary.LittleEndian.Uint32(content))
+
+	switch v.shapeType {
+	case common.NULL:
+		return v, nil
+	case common.POINT, common.POINTM, common.POINTZ:
+		v.numPoints = 1
+		v.pointsAt = 4
+
+		need := 20
+		switch v.shapeType {
+		case common.POINTZ:
+			v.zAt = 20
+			need = 28
+
+			// M is optional
+			if len(content) >= 36 {
+				v.mAt = 28
+			}
+		case common.POINTM:
+			v.mAt = 20
+			need = 28
+		}
+
+		if len(content) < need {
+			return v, fmt.Errorf(`record content is %d bytes, should be at least %d`, len(content), need)
+		}
+
+		return v, nil
+	case common.MULTIPOINT, common.MULTIPOINTM, common.MULTIPOINTZ:
+		if len(content) < 40 {
+			return v, fmt.Errorf(`record content is %d bytes, header doesn't fit`, len(content))
+		}
+
+		v.numPoints = int(binary.LittleEndian.Uint32(content[36:]))
+		v.pointsAt = 40
+	case common.POLYLINE, common.POLYLINEM, common.POLYLINEZ, common.POLYGON, common.POLYGONM, common.POLYGONZ, common.MULTIPATCH:
+		if len(content) < 44 {
+			return v, fmt.Errorf(`record content is %d bytes, header doesn't fit`, len(content))
+		}
+
+		v.numParts = int(binary.LittleEndian.Uint32(content[36:]))
+		v.numPoints = int(binary.LittleEndian.Uint32(content[40:]))
+		v.partsAt = 44
+
+		// Counts come from file, check before multiplying so that offsets can't overflow
+		if v.numParts > (len(content)-v.partsAt)/4 {
+			return v, &InvalidCount{Field: `NumParts`, Count: uint32(v.numParts), Need: int64(v.numParts) * 4, Remaining: int64(len(content) - v.partsAt)}
+		}
+
+		v.pointsAt = v.partsAt + 4*v.numParts
+
+		if v.shapeType == common.MULTIPATCH {
+			v.typesAt = v.pointsAt
+			v.pointsAt += 4 * v.numParts
+		}
+	default:
+		return v, &common.ErrInvalidShapeType{ShapeType: v.shapeType}
+	}
+
+	if v.pointsAt > len(content) || v.numPoints > (len(content)-v.pointsAt)/16 {
+		return v, &InvalidCount{Field: `NumPoints`, Count: uint32(v.numPoints), Need: int64(v.numPoints) * 16, Remaining: int64(len(content) - v.pointsAt)}
+	}
+
+	end := v.pointsAt + 16*v.numPoints
+
+	// Z and M arrays are preceded by 16 byte range
+	arr := 16 + 8*v.numPoints
+
+	switch v.shapeType {
+	case common.MULTIPOINTZ, common.POLYLINEZ, common.POLYGONZ, common.MULTIPATCH:
+		if end+arr > len(content) {
+			return v, fmt.Errorf(`record content is %d bytes, Z array doesn't fit`, len(content))
+		}
+
+		v.zAt = end + 16
+		end += arr
+
+		// M is optional
+		if end+arr <= len(content) {
+			v.mAt = end + 16
+		}
+	case common.MULTIPOINTM, common.POLYLINEM, common.POLYGONM:
+		// M is optional
+		if end+arr <= len(content) {
+			v.mAt = end + 16
+		}
+	}
+
+	return v, nil
+}
+
+func (v RecordView) ShapeType() common.ShapeType {
+	return v.shapeType
+}
+
+// Bounding box. Point types have box of single point and Null an empty box.
+func (v RecordView) Box() Box {
+	b, _ := recordBox(v.Content)
+	return b
+}
+
+func (v RecordView) NumParts() int {
+	return v.numParts
+}
+
+func (v RecordView) NumPoints() int {
+	return v.numPoints
+}
+
+// Index of first point of i:th part
+func (v RecordView) Part(i int) int {
+	return int(binary.LittleEndian.Uint32(v.Content[v.partsAt+4*i:]))
+}
+
+// Points of i:th part as [start, end) range of point indexes. Part indexes are not validated,
+// use Decode for validated shape.
+func (v RecordView) PartRange(i int) (start, end int) {
+	start = v.Part(i)
+	end = v.numPoints
+
+	if i+1 < v.numParts {
+		end = v.Part(i + 1)
+	}
+
+	return start, end
+}
+
+// Multi patch part type of i:th part
+func (v RecordView) PartType(i int) PartType {
+	return PartType(binary.LittleEndian.Uint32(v.Content[v.typesAt+4*i:]))
+}
+
+func (v RecordView) Point(i int) Point {
+	at := v.pointsAt + 16*i
+
+	return Point{
+		X: math.Float64frombits(binary.LittleEndian.Uint64(v.Content[at:])),
+		Y: math.Float64frombits(binary.LittleEndian.Uint64(v.Content[at+8:])),
+	}
+}
+
+func (v RecordView) HasZ() bool {
+	return v.zAt > 0
+}
+
+func (v RecordView) HasM() bool {
+	return v.mAt > 0
+}
+
+// Z of i:th point, see HasZ
+func (v RecordView) Z(i int) float64 {
+	return math.Float64frombits(binary.LittleEndian.Uint64(v.Content[v.zAt+8*i:]))
+}
+
+// M of i:th point, see HasM
+func (v RecordView) M(i int) float64 {
+	return math.Float64frombits(binary.LittleEndian.Uint64(v.Content[v.mAt+8*i:]))
+}
+
+// Decode and validate whole record, same as ReadRecord returns
+func (v RecordView) Decode() (ShapeTypeI, error) {
+	return DecodeRecord(v.Content)
+}
+
+/*
+Mapped reads .shp from byte slice without copying, normally memory mapped file from OpenMapped.
+Records are returned as views over the bytes.
+*/
+type Mapped struct {
+	Header1 common.ShapeFileHeader1
+	Header2 common.ShapeFileHeader2
+	data    []byte
+	offset  int64        // Next record read by Next
+	unmap   func() error // nil if data wasn't mapped by OpenMapped
+}
+
+// Read .shp from data, which must not be modified while views are in use
+func NewMapped(data []byte) (m *Mapped, err error) {
+	m = &Mapped{data: data, offset: 100}
+
+	r, err := common.NewReadSeekCloser(data[:minInt(len(data), 100)])
+	if err != nil {
+		return nil, err
+	}
+
+	m.Header1, m.Header2, err = common.ParseHeaders(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// Unmap file. Views must not be used after closing.
+func (m *Mapped) Close() error {
+	if m.unmap == nil {
+		return nil
+	}
+
+	err := m.unmap()
+	m.unmap = nil
+	m.data = nil
+	return err
+}
+
+// Next record, io.EOF after last one. After an invalid record Next continues from the one after it,
+// after a record which is cut by end of data Next returns io.EOF.
+func (m *Mapped) Next() (v RecordView, err error) {
+	return m.RecordAt(m.offset)
+}
+
+// Record at offset, for example from .shx. Next continues from next record. Offset outside of
+// record data is an error which doesn't change where Next continues.
+func (m *Mapped) RecordAt(offset int64) (v RecordView, err error) {
+	size := int64(len(m.data))
+
+	if offset == size {
+		return v, io.EOF
+	}
+
+	if offset < 100 || offset > size {
+		return v, fmt.Errorf(`record offset %d is outside of records at 100..%d`, offset, size)
+	}
+
+	if offset+8 > size {
+		// Record header cut by end of data
+		m.offset = size
+		return v, io.ErrUnexpectedEOF
+	}
+
+	number := binary.BigEndian.Uint32(m.data[offset:])
+	length := common.WordsToBytes(m.data[offset+4:])
+
+	if length > size-offset-8 {
+		m.offset = size
+		return v, &RecordPastEOF{Number: number, Length: length, Remaining: size - offset - 8}
+	}
+
+	content := m.data[offset+8 : offset+8+length]
+
+	m.offset = offset + 8 + length
+
+	if number == 0 {
+		return v, fmt.Errorf(`record at offset %d has number 0, numbers start from 1`, offset)
+	}
+
+	return NewRecordView(number-1, offset, content)
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,191 @@
+package shp
+
+import (
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// Compare view accessors with fields of decoded shape
+func checkView(t *testing.T, v RecordView) {
+	shape, err := v.Decode()
+	if err != nil {
+		t.Fatalf(`record #%d: %v`, v.Number, err)
+	}
+
+	s := reflect.ValueOf(shape)
+
+	if f := s.FieldByName(`Points`); f.IsValid() {
+		points := f.Interface().([]Point)
+		if len(points) != v.NumPoints() {
+			t.Fatalf(`record #%d has %d points, view has %d`, v.Number, len(points), v.NumPoints())
+		}
+
+		for i, p := range points {
+			if v.Point(i) != p {
+				t.Errorf(`record #%d point %d is %v, view has %v`, v.Number, i, p, v.Point(i))
+			}
+		}
+	} else if f := s.FieldByName(`X`); f.IsValid() {
+		p := Point{X: f.Float(), Y: s.FieldByName(`Y`).Float()}
+		if v.Point(0) != p {
+			t.Errorf(`record #%d is %v, view has %v`, v.Number, p, v.Point(0))
+		}
+	}
+
+	if f := s.FieldByName(`Parts`); f.IsValid() {
+		for i, part := range f.Interface().([]uint32) {
+			if v.Part(i) != int(part) {
+				t.Errorf(`record #%d part %d is %d, view has %d`, v.Number, i, part, v.Part(i))
+			}
+		}
+	}
+
+	for _, name := range []string{`ZArray`, `MArray`} {
+		f := s.FieldByName(name)
+		if !f.IsValid() {
+			continue
+		}
+
+		get, has := v.Z, v.HasZ()
+		if name == `MArray` {
+			get, has = v.M, v.HasM()
+		}
+
+		arr := f.Interface().([]float64)
+		if has != (len(arr) > 0) {
+			t.Fatalf(`record #%d %v has %d values, view has %v`, v.Number, name, len(arr), has)
+		}
+
+		for i, z := range arr {
+			if get(i) != z {
+				t.Errorf(`record #%d %v[%d] is %v, view has %v`, v.Number, name, i, z, get(i))
+			}
+		}
+	}
+}
+
+func TestMapped(t *testing.T) {
+	files, err := filepath.Glob(`../_test_files/*.shp`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, fpath := range files {
+		t.Run(filepath.Base(fpath), func(t *testing.T) {
+			data, err := ioutil.ReadFile(fpath)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			m, err := NewMapped(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for n := uint32(0); ; n++ {
+				v, err := m.Next()
+				if err == io.EOF {
+					break
+				}
+
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if v.Number != n {
+					t.Fatalf(`record number %d, should be %d`, v.Number, n)
+				}
+
+				checkView(t, v)
+			}
+		})
+	}
+}
+
+func TestRecordViewInvalidCount(t *testing.T) {
+	content := make([]byte, 44)
+	content[0] = 5                   // Polygon
+	content[36], content[40] = 1, 10 // 1 part, 10 points
+
+	_, err := NewRecordView(0, 100, content)
+	if _, ok := err.(*InvalidCount); !ok {
+		t.Fatalf(`expected *InvalidCount, got %v`, err)
+	}
+}
+
+// Caller skipping errors must reach io.EOF after truncated last record
+func TestMappedTruncated(t *testing.T) {
+	orig, err := ioutil.ReadFile(`../_test_files/point.shp`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Cut last record content, and last record header
+	for _, cut := range []int{4, 24} {
+		m, err := NewMapped(orig[:len(orig)-cut])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		records, errs := 0, 0
+		for i := 0; i < 10; i++ {
+			_, err := m.Next()
+			if err == io.EOF {
+				break
+			}
+
+			if err != nil {
+				errs++
+				continue
+			}
+
+			records++
+		}
+
+		if records != 2 || errs != 1 {
+			t.Errorf(`cut %d bytes: read %d records and %d errors, should be 2 and 1`, cut, records, errs)
+		}
+	}
+}
+
+// Wrong offset from caller doesn't move Next, record number 0 is skipped
+func TestMappedRecordAt(t *testing.T) {
+	orig, err := ioutil.ReadFile(`../_test_files/point.shp`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := append([]byte{}, orig...)
+	binary.BigEndian.PutUint32(b[128:], 0)
+
+	m, err := NewMapped(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, offset := range []int64{0, 99, int64(len(b)) + 8} {
+		_, err = m.RecordAt(offset)
+		if err == nil || err == io.EOF {
+			t.Fatalf(`offset %d: expected error, got %v`, offset, err)
+		}
+	}
+
+	v, err := m.Next()
+	if err != nil || v.Number != 0 {
+		t.Fatalf(`expected record #0 after invalid offsets, got #%d %v`, v.Number, err)
+	}
+
+	_, err = m.Next()
+	if err == nil {
+		t.Fatalf(`expected error for record number 0`)
+	}
+
+	v, err = m.Next()
+	if err != nil || v.Number != 2 {
+		t.Fatalf(`expected record #2 after record number 0, got #%d %v`, v.Number, err)
+	}
+}
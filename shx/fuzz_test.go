@@ -0,0 +1,45 @@
+package shx
+
+import (
+	"github.com/raspi/GeoESRIShapeFile/common"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func FuzzReadRecord(f *testing.F) {
+	files, err := filepath.Glob(`../_test_files/*.shx`)
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	for _, fpath := range files {
+		b, err := ioutil.ReadFile(fpath)
+		if err != nil {
+			f.Fatal(err)
+		}
+
+		f.Add(b)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		r, err := common.NewReadSeekCloser(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sfi := IndexRecordLookupFile{r: r}
+
+		err = sfi.Initialize()
+		if err != nil {
+			return
+		}
+
+		for i := uint(0); i < sfi.GetHeaderRecordCount(); i++ {
+			_, err = sfi.ReadRecord()
+			if err != nil {
+				return
+			}
+		}
+	})
+}
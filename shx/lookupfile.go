@@ -3,12 +3,13 @@ package shx
 import (
 	"github.com/raspi/GeoESRIShapeFile/common"
 	"io"
-	"log"
 )
 
 type IndexRecordLookupFile struct {
+	Header1       common.ShapeFileHeader1 // File code and length
+	Header2       common.ShapeFileHeader2 // Shape type and bounding box, same as in .shp
 	r             common.ReadSeekCloser
-	debug         bool
+	logger        common.Logger // nil for no logging
 	initialized   bool
 	totalFileSize uint
 	totalRecords  uint
@@ -20,27 +21,51 @@ func New(fname string) (sfi IndexRecordLookupFile, err error) {
 		return sfi, err
 	}
 
+	return NewFrom(f), nil
+}
+
+// Read from already opened file, for example from afero.Fs or fs.FS. Close closes r.
+func NewFrom(r common.ReadSeekCloser) IndexRecordLookupFile {
 	return IndexRecordLookupFile{
-		r:           f,
-		debug:       false,
+		r:           r,
 		initialized: false,
-	}, nil
+	}
+}
+
+// Read from r of given size, for example from object storage with ranged reads. ReadRecordAt is one ranged read.
+func NewReaderAt(r io.ReaderAt, size int64) IndexRecordLookupFile {
+	return NewFrom(common.NewReaderAt(r, size))
+}
+
+// Set logger, for example *slog.Logger. nil disables logging, which is the default.
+func (sfi *IndexRecordLookupFile) SetLogger(l common.Logger) {
+	sfi.logger = l
 }
 
+func (sfi *IndexRecordLookupFile) GetLogger() common.Logger {
+	return sfi.logger
+}
+
+// Deprecated: use SetLogger. true logs debug messages with standard log package.
 func (sfi *IndexRecordLookupFile) SetDebug(flag bool) {
-	sfi.debug = flag
+	sfi.logger = common.DebugLogger(flag)
 }
 
 func (sfi *IndexRecordLookupFile) GetDebug() bool {
-	return sfi.debug
+	return sfi.logger != nil
 }
 
 func (sfi *IndexRecordLookupFile) Close() error {
+	if sfi == nil || sfi.r == nil {
+		// Not opened
+		return nil
+	}
+
 	return sfi.r.Close()
 }
 
 func (sfi *IndexRecordLookupFile) Initialize() (err error) {
-	err = common.ReadHeaders(sfi.r)
+	sfi.Header1, sfi.Header2, err = common.ParseHeaders(sfi.r)
 	if err != nil {
 		return err
 	}
@@ -52,8 +77,8 @@ func (sfi *IndexRecordLookupFile) Initialize() (err error) {
 
 	sfi.totalFileSize += uint(offset)
 
-	if sfi.debug {
-		log.Printf(`header read successfully`)
+	if sfi.logger != nil {
+		sfi.logger.Debug(`header read successfully`, `records`, sfi.GetHeaderRecordCount())
 	}
 
 	sfi.initialized = true
@@ -67,3 +92,13 @@ func (sfi IndexRecordLookupFile) GetShapeFileSize() uint {
 func (sfi IndexRecordLookupFile) GetRecordCount() uint {
 	return sfi.totalRecords
 }
+
+// Record count calculated from file length in header. GetRecordCount only counts records read so far.
+func (sfi IndexRecordLookupFile) GetHeaderRecordCount() uint {
+	length := uint(sfi.Header1.Length) * 2
+	if length < 100 {
+		return 0
+	}
+
+	return (length - 100) / 8
+}
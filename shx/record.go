@@ -4,6 +4,8 @@ import (
 	"encoding/binary"
 	"fmt"
 	"github.com/raspi/GeoESRIShapeFile/common"
+	"io"
+	"math"
 )
 
 // Offsets for .shp file
@@ -16,18 +18,55 @@ func (oi ShapeIndexRecord) String() string {
 	return fmt.Sprintf(`offset 0x%04[1]x (%06[1]d) with len 0x%04[2]x (%06[2]d)`, oi.Offset, oi.Length)
 }
 
+// Index entry with 64-bit offset and length in bytes, see ReadRecordAt
+type Entry struct {
+	Offset int64 // Offset of record header in .shp
+	Length int64 // Record content length
+}
+
+func (e Entry) String() string {
+	return fmt.Sprintf(`offset 0x%04[1]x (%06[1]d) with len 0x%04[2]x (%06[2]d)`, e.Offset, e.Length)
+}
+
+// Entry doesn't fit 32-bit ShapeIndexRecord, use ReadRecordAt
+type EntryTooLarge struct {
+	Entry Entry
+}
+
+func (e *EntryTooLarge) Error() string {
+	return fmt.Sprintf(`index entry %v doesn't fit 32 bits`, e.Entry)
+}
+
+// Read 8 byte entry and convert words to bytes
+func (sfi *IndexRecordLookupFile) readEntry() (e Entry, err error) {
+	var buf [8]byte
+	_, err = io.ReadFull(sfi.r, buf[:])
+	if err != nil {
+		return e, err
+	}
+
+	e.Offset = common.WordsToBytes(buf[0:])
+	e.Length = common.WordsToBytes(buf[4:])
+
+	return e, nil
+}
+
 func (sfi *IndexRecordLookupFile) ReadRecord() (o ShapeIndexRecord, err error) {
 	if !sfi.initialized {
 		return o, common.ErrorNotInitialized
 	}
 
-	err = binary.Read(sfi.r, binary.BigEndian, &o)
+	e, err := sfi.readEntry()
 	if err != nil {
 		return o, err
 	}
 
-	o.Offset *= 2
-	o.Length *= 2
+	if e.Offset > math.MaxUint32 || e.Length > math.MaxUint32 {
+		return o, &EntryTooLarge{Entry: e}
+	}
+
+	o.Offset = uint32(e.Offset)
+	o.Length = uint32(e.Length)
 
 	sfi.totalFileSize += uint(o.Length)
 	sfi.totalFileSize += 8 // Meta data
@@ -35,3 +74,31 @@ func (sfi *IndexRecordLookupFile) ReadRecord() (o ShapeIndexRecord, err error) {
 
 	return o, nil
 }
+
+// Read n:th (0-based) index entry
+func (sfi *IndexRecordLookupFile) ReadRecordAt(n uint) (e Entry, err error) {
+	if !sfi.initialized {
+		return e, common.ErrorNotInitialized
+	}
+
+	_, err = sfi.r.Seek(100+int64(n)*int64(binary.Size(ShapeIndexRecord{})), io.SeekStart)
+	if err != nil {
+		return e, err
+	}
+
+	return sfi.readEntry()
+}
+
+// Offset of n:th (0-based) record in .shp, see shp.RecordIndex. Returns io.EOF if n is past last entry.
+func (sfi *IndexRecordLookupFile) RecordOffset(n uint32) (offset int64, err error) {
+	if uint(n) >= sfi.GetHeaderRecordCount() {
+		return 0, io.EOF
+	}
+
+	e, err := sfi.ReadRecordAt(uint(n))
+	if err != nil {
+		return 0, err
+	}
+
+	return e.Offset, nil
+}
@@ -2,6 +2,8 @@ package shx
 
 import (
 	"encoding/binary"
+	"github.com/raspi/GeoESRIShapeFile/common"
+	"io/ioutil"
 	"testing"
 )
 
@@ -11,3 +13,44 @@ func TestIndexRecordFileSize(t *testing.T) {
 		t.Fatalf(`record size was %v, should be 8`, actual)
 	}
 }
+
+// Offsets of 2^31 words or more must not wrap around
+func TestReadRecordLargeOffset(t *testing.T) {
+	b, err := ioutil.ReadFile(`../_test_files/point.shx`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	binary.BigEndian.PutUint32(b[100:], 0x80000000)
+
+	r, err := common.NewReadSeekCloser(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sfi := NewFrom(r)
+	err = sfi.Initialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// ShapeIndexRecord can't hold the offset
+	_, err = sfi.ReadRecord()
+	if _, ok := err.(*EntryTooLarge); !ok {
+		t.Fatalf(`expected EntryTooLarge, got %v`, err)
+	}
+
+	e, err := sfi.ReadRecordAt(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e.Offset != 0x100000000 || e.Length != 20 {
+		t.Fatalf(`read %v, should be offset 0x100000000 with length 20`, e)
+	}
+
+	offset, err := sfi.RecordOffset(0)
+	if err != nil || offset != 0x100000000 {
+		t.Fatalf(`RecordOffset returned %d, %v`, offset, err)
+	}
+}
@@ -0,0 +1,112 @@
+package validate
+
+import (
+	"fmt"
+)
+
+type Severity int
+
+const (
+	Info    Severity = iota // Unusual but allowed by spec
+	Warning                 // Violates spec, but most readers cope with it
+	Error                   // Violates spec, readers may fail or return wrong data
+)
+
+func (s Severity) String() string {
+	switch s {
+	case Info:
+		return `info`
+	case Warning:
+		return `warning`
+	case Error:
+		return `error`
+	default:
+		return fmt.Sprintf(`severity(%d)`, int(s))
+	}
+}
+
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Check identifies which check produced a finding
+type Check string
+
+const (
+	CheckMissingFile     Check = `missing-file`     // .shx or .dbf is missing
+	CheckHeader          Check = `header`           // File code, version, shape type
+	CheckHeaderMismatch  Check = `header-mismatch`  // .shp and .shx headers differ
+	CheckFileLength      Check = `file-length`      // Length in header vs. actual file size
+	CheckTruncated       Check = `truncated`        // Record continues past end of file
+	CheckRecordNumber    Check = `record-number`    // Record numbers are sequential and start from 1
+	CheckRecordLength    Check = `record-length`    // Content length vs. decoded shape
+	CheckShapeType       Check = `shape-type`       // Record type is Null or same as in header
+	CheckDecode          Check = `decode`           // Record content couldn't be decoded
+	CheckGeometry        Check = `geometry`         // Counts and Z/M arrays, see shp.ShapeTypeI Validate()
+	CheckBox             Check = `bbox`             // Bounding boxes contain their points
+	CheckParts           Check = `parts`            // Parts indices are monotonic and in range
+	CheckRingClosed      Check = `ring-closed`      // Polygon rings end at their first point
+	CheckRingPoints      Check = `ring-points`      // Polygon rings have at least 4 points
+	CheckRingOrientation Check = `ring-orientation` // Outer rings are clockwise, holes counter-clockwise
+	CheckShxCount        Check = `shx-count`        // .shx has one entry per .shp record
+	CheckShxOffset       Check = `shx-offset`       // .shx offset points to record header
+	CheckShxLength       Check = `shx-length`       // .shx length equals record header length
+	CheckDbfCount        Check = `dbf-count`        // .dbf has one row per .shp record
+)
+
+// Finding is a single problem found in dataset
+type Finding struct {
+	Severity Severity `json:"severity"`
+	Check    Check    `json:"check"`
+	File     string   `json:"file"`             // Extension without dot: shp, shx or dbf
+	Offset   int64    `json:"offset"`           // Byte offset in File, -1 if not about a specific location
+	Record   uint32   `json:"record,omitempty"` // 1-based record number, 0 if not about a record
+	Message  string   `json:"message"`
+}
+
+func (f Finding) String() string {
+	s := fmt.Sprintf(`%v: %v`, f.Severity, f.File)
+
+	if f.Offset >= 0 {
+		s += fmt.Sprintf(` offset 0x%04[1]x (%06[1]d)`, f.Offset)
+	}
+
+	if f.Record > 0 {
+		s += fmt.Sprintf(` record #%d`, f.Record)
+	}
+
+	return s + fmt.Sprintf(`: %v [%v]`, f.Message, f.Check)
+}
+
+// Report is the result of validating one dataset
+type Report struct {
+	Path     string    `json:"path"`    // .shp file
+	Records  int       `json:"records"` // Records found in .shp
+	Findings []Finding `json:"findings"`
+}
+
+// How many findings have given severity
+func (r Report) Count(s Severity) (count int) {
+	for _, f := range r.Findings {
+		if f.Severity == s {
+			count++
+		}
+	}
+
+	return count
+}
+
+func (r Report) HasErrors() bool {
+	return r.Count(Error) > 0
+}
+
+func (r *Report) add(s Severity, c Check, file string, offset int64, record uint32, format string, args ...interface{}) {
+	r.Findings = append(r.Findings, Finding{
+		Severity: s,
+		Check:    c,
+		File:     file,
+		Offset:   offset,
+		Record:   record,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
@@ -0,0 +1,207 @@
+package validate
+
+import (
+	"github.com/raspi/GeoESRIShapeFile/shp"
+)
+
+// Fields shared by shape types. Offsets are relative to start of record content.
+type geometry struct {
+	box      *shp.Box // nil for point types
+	parts    []uint32
+	points   []shp.Point
+	partsAt  int64
+	pointsAt int64
+	polygon  bool // Parts are rings
+	hasParts bool
+}
+
+/*
+Offsets (see shp package):
+Point types: X at byte 4
+Multi point types: Points at byte 40
+Poly line and polygon types: Parts at byte 44, Points at 44 + 4 * NumParts
+Multi patch: Parts at byte 44, Points at 44 + 8 * NumParts (part types in between)
+*/
+func geometryOf(shape shp.ShapeTypeI) (g geometry, ok bool) {
+	poly := func(box shp.Box, parts []uint32, points []shp.Point, polygon bool) geometry {
+		return geometry{box: &box, parts: parts, points: points, partsAt: 44, pointsAt: 44 + 4*int64(len(parts)), polygon: polygon, hasParts: true}
+	}
+
+	multi := func(box shp.Box, points []shp.Point) geometry {
+		return geometry{box: &box, points: points, pointsAt: 40}
+	}
+
+	switch s := shape.(type) {
+	case shp.Point:
+		return geometry{points: []shp.Point{s}, pointsAt: 4}, true
+	case shp.PointM:
+		return geometry{points: []shp.Point{{X: s.X, Y: s.Y}}, pointsAt: 4}, true
+	case shp.PointZ:
+		return geometry{points: []shp.Point{{X: s.X, Y: s.Y}}, pointsAt: 4}, true
+	case shp.MultiPoint:
+		return multi(s.Box, s.Points), true
+	case shp.MultiPointM:
+		return multi(s.Box, s.Points), true
+	case shp.MultiPointZ:
+		return multi(s.Box, s.Points), true
+	case shp.PolyLine:
+		return poly(s.Box, s.Parts, s.Points, false), true
+	case shp.PolyLineM:
+		return poly(s.Box, s.Parts, s.Points, false), true
+	case shp.PolyLineZ:
+		return poly(s.Box, s.Parts, s.Points, false), true
+	case shp.Polygon:
+		return poly(s.Box, s.Parts, s.Points, true), true
+	case shp.PolygonM:
+		return poly(s.Box, s.Parts, s.Points, true), true
+	case shp.PolygonZ:
+		return poly(s.Box, s.Parts, s.Points, true), true
+	case shp.MultiPatch:
+		g = poly(s.Box, s.Parts, s.Points, false)
+		g.pointsAt = 44 + 8*int64(len(s.Parts))
+		return g, true
+	default:
+		// Null
+		return g, false
+	}
+}
+
+// Check decoded shape. offset is start of record content.
+func (v *validator) checkShape(n uint32, offset int64, shape shp.ShapeTypeI) {
+	g, ok := geometryOf(shape)
+	if !ok {
+		return
+	}
+
+	if g.box != nil {
+		v.checkBox(n, offset, g)
+		v.extendBox(*g.box)
+	} else if len(g.points) > 0 {
+		p := g.points[0]
+		v.extendBox(shp.Box{MinX: p.X, MinY: p.Y, MaxX: p.X, MaxY: p.Y})
+	}
+
+	if g.hasParts && !v.checkParts(n, offset, g) {
+		// Validate() would report the same problem
+		return
+	}
+
+	err := shape.Validate()
+	if err != nil {
+		v.report.add(Error, CheckGeometry, `shp`, offset, n, `%v`, err)
+		return
+	}
+
+	if g.polygon {
+		v.checkRings(n, offset, g)
+	}
+}
+
+// Record box must be valid and contain all points. Only first point outside is reported.
+func (v *validator) checkBox(n uint32, offset int64, g geometry) {
+	b := *g.box
+
+	if b.MinX > b.MaxX || b.MinY > b.MaxY {
+		v.report.add(Error, CheckBox, `shp`, offset+4, n, `box (%v) has min larger than max`, b)
+		return
+	}
+
+	for idx, p := range g.points {
+		if !b.Contains(p) {
+			v.report.add(Error, CheckBox, `shp`, offset+g.pointsAt+16*int64(idx), n, `point #%d (%v) is outside record box (%v)`, idx, p, b)
+			return
+		}
+	}
+}
+
+// Parts must start from 0, be strictly increasing (no empty parts) and point to existing points.
+// Only first problem is reported.
+func (v *validator) checkParts(n uint32, offset int64, g geometry) bool {
+	if len(g.parts) == 0 {
+		if len(g.points) > 0 {
+			v.report.add(Error, CheckParts, `shp`, offset+36, n, `%d points but no parts`, len(g.points))
+			return false
+		}
+
+		return true
+	}
+
+	for idx, p := range g.parts {
+		at := offset + g.partsAt + 4*int64(idx)
+
+		switch {
+		case idx == 0 && p != 0:
+			v.report.add(Error, CheckParts, `shp`, at, n, `first part starts at %d, should be 0`, p)
+		case int(p) >= len(g.points):
+			v.report.add(Error, CheckParts, `shp`, at, n, `part #%d starts at %d, there are %d points`, idx, p, len(g.points))
+		case idx > 0 && p <= g.parts[idx-1]:
+			v.report.add(Error, CheckParts, `shp`, at, n, `part #%d starts at %d, previous part starts at %d`, idx, p, g.parts[idx-1])
+		default:
+			continue
+		}
+
+		return false
+	}
+
+	return true
+}
+
+// Rings must be closed and have at least 4 points. Outer rings must be clockwise and holes
+// counter-clockwise. Ring is a hole if it is inside an odd number of other rings.
+func (v *validator) checkRings(n uint32, offset int64, g geometry) {
+	rings := make([][]shp.Point, len(g.parts))
+	for idx := range g.parts {
+		end := len(g.points)
+		if idx+1 < len(g.parts) {
+			end = int(g.parts[idx+1])
+		}
+
+		rings[idx] = g.points[g.parts[idx]:end]
+	}
+
+	for idx, ring := range rings {
+		at := offset + g.pointsAt + 16*int64(g.parts[idx])
+
+		if len(ring) < 4 {
+			v.report.add(Error, CheckRingPoints, `shp`, at, n, `ring #%d has %d points, at least 4 required`, idx, len(ring))
+			continue
+		}
+
+		if ring[0] != ring[len(ring)-1] {
+			v.report.add(Error, CheckRingClosed, `shp`, at, n, `ring #%d is not closed, first point is %v and last point is %v`, idx, ring[0], ring[len(ring)-1])
+			continue
+		}
+
+		area := shp.RingSignedArea(ring)
+		if area == 0 {
+			v.report.add(Warning, CheckRingOrientation, `shp`, at, n, `ring #%d has zero area`, idx)
+			continue
+		}
+
+		hole := false
+		for other := range rings {
+			if other != idx && ringInside(ring, rings[other]) {
+				hole = !hole
+			}
+		}
+
+		switch {
+		case hole && area < 0:
+			v.report.add(Warning, CheckRingOrientation, `shp`, at, n, `ring #%d is a hole but clockwise`, idx)
+		case !hole && area > 0:
+			v.report.add(Warning, CheckRingOrientation, `shp`, at, n, `ring #%d is an outer ring but counter-clockwise`, idx)
+		}
+	}
+}
+
+// Is ring inside other ring. Rings may touch, so majority of points must be inside.
+func ringInside(ring, other []shp.Point) bool {
+	inside := 0
+	for _, p := range ring {
+		if shp.RingContains(other, p) {
+			inside++
+		}
+	}
+
+	return inside*2 > len(ring)
+}
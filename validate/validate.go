@@ -0,0 +1,325 @@
+package validate
+
+import (
+	"encoding/binary"
+	"github.com/raspi/GeoESRIShapeFile/common"
+	"github.com/raspi/GeoESRIShapeFile/dbf"
+	"github.com/raspi/GeoESRIShapeFile/shp"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	headerSize         = 100
+	headerLengthOffset = 24 // File length in 16-bit words, big endian
+	headerTypeOffset   = 32 // Shape type, little endian
+	headerBoxOffset    = 36
+	recordHeaderSize   = 8
+	shxEntrySize       = 8
+	dbfRecordCountAt   = 4
+)
+
+// Where a record was found in .shp
+type recordLocation struct {
+	offset int64 // Record header
+	length int64 // Content length in bytes
+}
+
+type validator struct {
+	report  Report
+	hdr     common.ShapeFileHeader2 // .shp header
+	records []recordLocation
+	box     shp.Box // Union of record boxes
+	hasBox  bool
+}
+
+/*
+Validate dataset against ESRI shapefile specification. fpath is the .shp file, .shx and .dbf are
+looked up by base name. Problems in files are reported as findings, err is only returned if
+the .shp file can't be read at all.
+*/
+func Dataset(fpath string) (r Report, err error) {
+	files, err := findFiles(fpath)
+	if err != nil {
+		return r, err
+	}
+
+	v := validator{}
+	v.report.Path = files[`shp`]
+
+	f, err := os.Open(files[`shp`])
+	if err != nil {
+		return r, err
+	}
+	defer f.Close()
+
+	_, hdr, size, ok := v.checkHeader(`shp`, f)
+	if ok {
+		v.hdr = hdr
+		err = v.checkRecords(f, size)
+		if err != nil {
+			return v.report, err
+		}
+	}
+
+	if p, ok := files[`shx`]; ok {
+		err = v.checkShx(p)
+		if err != nil {
+			return v.report, err
+		}
+	} else {
+		v.report.add(Error, CheckMissingFile, `shx`, -1, 0, `.shx file is missing`)
+	}
+
+	if p, ok := files[`dbf`]; ok {
+		v.checkDbf(p)
+	} else {
+		v.report.add(Error, CheckMissingFile, `dbf`, -1, 0, `.dbf file is missing`)
+	}
+
+	return v.report, nil
+}
+
+// Find .shp, .shx and .dbf with same base name, extension is not case sensitive
+func findFiles(fpath string) (files map[string]string, err error) {
+	dir, fname := filepath.Split(fpath)
+	base := strings.TrimSuffix(fname, filepath.Ext(fname))
+
+	if dir == `` {
+		dir = `.`
+	}
+
+	flist, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	files = make(map[string]string)
+
+	for _, f := range flist {
+		if f.IsDir() {
+			continue
+		}
+
+		ext := filepath.Ext(f.Name())
+		if strings.TrimSuffix(f.Name(), ext) != base {
+			continue
+		}
+
+		ext = strings.ToLower(strings.TrimPrefix(ext, `.`))
+		switch ext {
+		case `shp`, `shx`, `dbf`:
+			files[ext] = filepath.Join(dir, f.Name())
+		}
+	}
+
+	if _, ok := files[`shp`]; !ok {
+		return nil, &os.PathError{Op: `open`, Path: filepath.Join(dir, base+`.shp`), Err: os.ErrNotExist}
+	}
+
+	return files, nil
+}
+
+// Check header shared by .shp and .shx and that header file length matches actual size.
+// ok is false if rest of the file can't be checked.
+func (v *validator) checkHeader(ext string, f *os.File) (hdr1 common.ShapeFileHeader1, hdr2 common.ShapeFileHeader2, size int64, ok bool) {
+	fi, err := f.Stat()
+	if err != nil {
+		v.report.add(Error, CheckHeader, ext, -1, 0, `couldn't stat file: %v`, err)
+		return hdr1, hdr2, size, false
+	}
+
+	size = fi.Size()
+
+	if size < headerSize {
+		v.report.add(Error, CheckTruncated, ext, 0, 0, `file is %d bytes, header alone is %d bytes`, size, headerSize)
+		return hdr1, hdr2, size, false
+	}
+
+	hdr1, hdr2, err = common.ParseHeaders(f)
+	if err != nil {
+		v.report.add(Error, CheckHeader, ext, 0, 0, `%v`, err)
+		return hdr1, hdr2, size, false
+	}
+
+	if length := int64(hdr1.Length) * 2; length != size {
+		v.report.add(Error, CheckFileLength, ext, headerLengthOffset, 0, `header file length is %d bytes, actual size is %d bytes`, length, size)
+	}
+
+	return hdr1, hdr2, size, true
+}
+
+// Walk all records in .shp up to actual end of file
+func (v *validator) checkRecords(f *os.File, size int64) (err error) {
+	offset := int64(headerSize)
+
+	for n := uint32(1); offset < size; n++ {
+		if size-offset < recordHeaderSize {
+			v.report.add(Error, CheckTruncated, `shp`, offset, n, `%d trailing bytes, record header needs %d`, size-offset, recordHeaderSize)
+			break
+		}
+
+		var hdr [recordHeaderSize]byte
+		_, err = f.ReadAt(hdr[:], offset)
+		if err != nil {
+			return err
+		}
+
+		number := binary.BigEndian.Uint32(hdr[0:])
+		length := common.WordsToBytes(hdr[4:])
+
+		if number != n {
+			v.report.add(Error, CheckRecordNumber, `shp`, offset, n, `record number is %d, should be %d`, number, n)
+		}
+
+		if length > size-offset-recordHeaderSize {
+			v.report.add(Error, CheckTruncated, `shp`, offset, n, `content length is %d bytes, only %d bytes left in file`, length, size-offset-recordHeaderSize)
+			break
+		}
+
+		content := make([]byte, length)
+		_, err = f.ReadAt(content, offset+recordHeaderSize)
+		if err != nil && err != io.EOF {
+			return err
+		}
+
+		v.records = append(v.records, recordLocation{offset: offset, length: length})
+		v.checkRecord(n, offset+recordHeaderSize, content)
+
+		offset += recordHeaderSize + length
+	}
+
+	v.report.Records = len(v.records)
+
+	if v.hasBox {
+		v.checkHeaderBox()
+	}
+
+	return nil
+}
+
+// Check record content. offset is start of content, after record header.
+func (v *validator) checkRecord(n uint32, offset int64, content []byte) {
+	if len(content) < 4 {
+		v.report.add(Error, CheckRecordLength, `shp`, offset, n, `content length is %d bytes, shape type alone needs 4`, len(content))
+		return
+	}
+
+	st := common.ShapeType(binary.LittleEndian.Uint32(content))
+	if st != common.NULL && st != v.hdr.ShapeType {
+		v.report.add(Error, CheckShapeType, `shp`, offset, n, `record shape type is %v, header shape type is %v`, st, v.hdr.ShapeType)
+	}
+
+	shape, err := shp.ParseRecord(content)
+	if err != nil {
+		v.report.add(Error, CheckDecode, `shp`, offset, n, `%v`, err)
+		return
+	}
+
+	v.checkShape(n, offset, shape)
+}
+
+// Header box must contain all record boxes
+func (v *validator) checkHeaderBox() {
+	h := shp.Box{MinX: v.hdr.Min.X, MinY: v.hdr.Min.Y, MaxX: v.hdr.Max.X, MaxY: v.hdr.Max.Y}
+
+	if h.MinX > v.box.MinX || h.MinY > v.box.MinY || h.MaxX < v.box.MaxX || h.MaxY < v.box.MaxY {
+		v.report.add(Error, CheckBox, `shp`, headerBoxOffset, 0, `header box (%v) doesn't contain all records (%v)`, h, v.box)
+	} else if h != v.box {
+		v.report.add(Warning, CheckBox, `shp`, headerBoxOffset, 0, `header box (%v) is larger than records (%v)`, h, v.box)
+	}
+}
+
+// Add record box to union of all boxes
+func (v *validator) extendBox(b shp.Box) {
+	if !v.hasBox {
+		v.box = b
+		v.hasBox = true
+		return
+	}
+
+	v.box.MinX = math.Min(v.box.MinX, b.MinX)
+	v.box.MinY = math.Min(v.box.MinY, b.MinY)
+	v.box.MaxX = math.Max(v.box.MaxX, b.MaxX)
+	v.box.MaxY = math.Max(v.box.MaxY, b.MaxY)
+}
+
+// Compare .shx header and entries with records found in .shp
+func (v *validator) checkShx(fpath string) (err error) {
+	f, err := os.Open(fpath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, hdr, size, ok := v.checkHeader(`shx`, f)
+	if !ok {
+		return nil
+	}
+
+	if hdr.ShapeType != v.hdr.ShapeType {
+		v.report.add(Error, CheckHeaderMismatch, `shx`, headerTypeOffset, 0, `shape type is %v, .shp has %v`, hdr.ShapeType, v.hdr.ShapeType)
+	}
+
+	if hdr.Min != v.hdr.Min || hdr.Max != v.hdr.Max || hdr.Z != v.hdr.Z || hdr.M != v.hdr.M {
+		v.report.add(Warning, CheckHeaderMismatch, `shx`, headerBoxOffset, 0, `bounding box differs from .shp header`)
+	}
+
+	if (size-headerSize)%shxEntrySize != 0 {
+		v.report.add(Error, CheckTruncated, `shx`, size-(size-headerSize)%shxEntrySize, 0, `%d trailing bytes after last entry`, (size-headerSize)%shxEntrySize)
+	}
+
+	count := int((size - headerSize) / shxEntrySize)
+	if count != len(v.records) {
+		v.report.add(Error, CheckShxCount, `shx`, -1, 0, `%d entries, .shp has %d records`, count, len(v.records))
+	}
+
+	if count > len(v.records) {
+		count = len(v.records)
+	}
+
+	entries := make([]byte, count*shxEntrySize)
+	_, err = f.ReadAt(entries, headerSize)
+	if err != nil && err != io.EOF {
+		return err
+	}
+
+	for idx, rec := range v.records[:count] {
+		at := int64(headerSize + idx*shxEntrySize)
+		offset := common.WordsToBytes(entries[idx*shxEntrySize:])
+		length := common.WordsToBytes(entries[idx*shxEntrySize+4:])
+
+		if offset != rec.offset {
+			v.report.add(Error, CheckShxOffset, `shx`, at, uint32(idx+1), `offset is %d, record is at %d`, offset, rec.offset)
+		}
+
+		if length != rec.length {
+			v.report.add(Error, CheckShxLength, `shx`, at+4, uint32(idx+1), `length is %d, record content length is %d`, length, rec.length)
+		}
+	}
+
+	return nil
+}
+
+func (v *validator) checkDbf(fpath string) {
+	db, err := dbf.Open(fpath)
+	if err != nil {
+		v.report.add(Error, CheckHeader, `dbf`, -1, 0, `%v`, err)
+		return
+	}
+	defer db.Close()
+
+	err = db.Initialize()
+	if err != nil {
+		v.report.add(Error, CheckHeader, `dbf`, 0, 0, `%v`, err)
+		return
+	}
+
+	if db.Header.RecordCount != len(v.records) {
+		v.report.add(Error, CheckDbfCount, `dbf`, dbfRecordCountAt, 0, `%d rows, .shp has %d records`, db.Header.RecordCount, len(v.records))
+	}
+}
@@ -0,0 +1,192 @@
+package validate
+
+import (
+	"encoding/binary"
+	"github.com/raspi/GeoESRIShapeFile/shp"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func hasFinding(r Report, c Check, offset int64) bool {
+	for _, f := range r.Findings {
+		if f.Check == c && f.Offset == offset {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestValidDataset(t *testing.T) {
+	r, err := Dataset(filepath.Join(`..`, `_test_files`, `polygon.shp`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(r.Findings) != 0 {
+		t.Fatalf(`expected no findings, got %v`, r.Findings)
+	}
+
+	if r.Records != 1 {
+		t.Fatalf(`expected 1 record, got %d`, r.Records)
+	}
+}
+
+func TestBrokenDataset(t *testing.T) {
+	dir, err := ioutil.TempDir(``, `validate`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, ext := range []string{`shp`, `shx`} {
+		b, err := ioutil.ReadFile(filepath.Join(`..`, `_test_files`, `polyline.`+ext))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if ext == `shp` {
+			// Second record number 2 -> 3 and cut last 4 bytes
+			second := 100 + 8 + 2*binary.BigEndian.Uint32(b[104:])
+			binary.BigEndian.PutUint32(b[second:], 3)
+			b = b[:len(b)-4]
+		}
+
+		err = ioutil.WriteFile(filepath.Join(dir, `broken.`+ext), b, 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r, err := Dataset(filepath.Join(dir, `broken.shp`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []Check{CheckFileLength, CheckRecordNumber, CheckTruncated, CheckShxCount, CheckMissingFile} {
+		found := false
+		for _, f := range r.Findings {
+			if f.Check == c {
+				found = true
+			}
+		}
+
+		if !found {
+			t.Errorf(`expected %v finding, got %v`, c, r.Findings)
+		}
+	}
+
+	if !hasFinding(r, CheckFileLength, headerLengthOffset) {
+		t.Errorf(`file length finding should point to header length`)
+	}
+}
+
+func TestCheckRings(t *testing.T) {
+	v := validator{}
+
+	// Counter-clockwise outer ring, clockwise hole and an open ring
+	p := shp.Polygon{
+		Box:       shp.Box{MinX: 0, MinY: 0, MaxX: 10, MaxY: 10},
+		NumParts:  3,
+		NumPoints: 13,
+		Parts:     []uint32{0, 5, 10},
+		Points: []shp.Point{
+			{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 10, Y: 10}, {X: 0, Y: 10}, {X: 0, Y: 0},
+			{X: 2, Y: 2}, {X: 2, Y: 4}, {X: 4, Y: 4}, {X: 4, Y: 2}, {X: 2, Y: 2},
+			{X: 6, Y: 6}, {X: 6, Y: 8}, {X: 8, Y: 8},
+		},
+	}
+
+	v.checkShape(1, 0, p)
+
+	pointsAt := int64(44 + 4*3)
+	for _, c := range []struct {
+		check  Check
+		offset int64
+	}{
+		{CheckRingOrientation, pointsAt},
+		{CheckRingOrientation, pointsAt + 16*5},
+		{CheckRingPoints, pointsAt + 16*10},
+	} {
+		if !hasFinding(v.report, c.check, c.offset) {
+			t.Errorf(`expected %v at %d, got %v`, c.check, c.offset, v.report.Findings)
+		}
+	}
+
+	if len(v.report.Findings) != 3 {
+		t.Errorf(`expected 3 findings, got %v`, v.report.Findings)
+	}
+}
+
+func TestCheckParts(t *testing.T) {
+	v := validator{}
+
+	p := shp.PolyLine{
+		Box:       shp.Box{MinX: 0, MinY: 0, MaxX: 1, MaxY: 1},
+		NumParts:  2,
+		NumPoints: 3,
+		Parts:     []uint32{0, 5},
+		Points:    []shp.Point{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: 2, Y: 1}},
+	}
+
+	v.checkShape(1, 0, p)
+
+	if !hasFinding(v.report, CheckParts, 44+4) {
+		t.Errorf(`expected parts finding, got %v`, v.report.Findings)
+	}
+
+	if !hasFinding(v.report, CheckBox, 44+8+16*2) {
+		t.Errorf(`expected box finding, got %v`, v.report.Findings)
+	}
+}
+
+// Decoding allows first part not starting from 0, so it's reported here
+func TestCheckFirstPart(t *testing.T) {
+	v := validator{}
+
+	p := shp.PolyLine{
+		Box:       shp.Box{MinX: 0, MinY: 0, MaxX: 1, MaxY: 1},
+		NumParts:  1,
+		NumPoints: 2,
+		Parts:     []uint32{1},
+		Points:    []shp.Point{{X: 0, Y: 0}, {X: 1, Y: 1}},
+	}
+
+	v.checkShape(1, 0, p)
+
+	if !hasFinding(v.report, CheckParts, 44) {
+		t.Errorf(`expected parts finding, got %v`, v.report.Findings)
+	}
+}
+
+// Content length of 2^31 words or more must not wrap around when converted to bytes
+func TestHugeRecordLength(t *testing.T) {
+	dir, err := ioutil.TempDir(``, `validate`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	b, err := ioutil.ReadFile(filepath.Join(`..`, `_test_files`, `point.shp`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	binary.BigEndian.PutUint32(b[104:], 0x8000000a)
+
+	err = ioutil.WriteFile(filepath.Join(dir, `huge.shp`), b, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := Dataset(filepath.Join(dir, `huge.shp`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !hasFinding(r, CheckTruncated, 100) || r.Records != 0 {
+		t.Errorf(`expected truncated finding and no records, got %d records and %v`, r.Records, r.Findings)
+	}
+}
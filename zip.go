@@ -0,0 +1,94 @@
+package geoesrishapefile
+
+import (
+	"archive/zip"
+	"io"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Zip archive containing one or more datasets. Files are decompressed to memory when dataset is opened.
+type Archive struct {
+	zr     *zip.Reader
+	closer io.Closer // nil if archive wasn't opened from path
+}
+
+// Dataset in zip archive
+type Dataset struct {
+	Name  string            // Slash separated path without extension, for example "data/roads"
+	Files map[string]string // Lower case extension without dot -> path in archive, includes sidecars such as .prj and .cpg
+}
+
+// Open zip archive from disk
+func OpenZip(fpath string) (*Archive, error) {
+	zrc, err := zip.OpenReader(fpath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Archive{zr: &zrc.Reader, closer: zrc}, nil
+}
+
+// Read zip archive of given size from r
+func NewZip(r io.ReaderAt, size int64) (*Archive, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Archive{zr: zr}, nil
+}
+
+// Close archive opened with OpenZip. Datasets opened from archive are in memory and must be closed separately.
+func (a *Archive) Close() error {
+	if a.closer == nil {
+		return nil
+	}
+
+	return a.closer.Close()
+}
+
+// Datasets having .shp file, sorted by name. Files are grouped by path without extension.
+func (a *Archive) Datasets() (list []Dataset) {
+	groups := make(map[string]map[string]string)
+
+	for _, f := range a.zr.File {
+		if f.FileInfo().IsDir() || isArchiveJunk(f.Name) {
+			continue
+		}
+
+		ext := path.Ext(f.Name)
+		name := strings.TrimSuffix(f.Name, ext)
+
+		if groups[name] == nil {
+			groups[name] = make(map[string]string)
+		}
+
+		groups[name][strings.ToLower(strings.TrimLeft(ext, `.`))] = f.Name
+	}
+
+	for name, files := range groups {
+		if _, ok := files[`shp`]; !ok {
+			continue
+		}
+
+		list = append(list, Dataset{Name: name, Files: files})
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+
+	return list
+}
+
+// Open dataset from archive by name as returned by Datasets, or path of .shp in archive. Use Close to free it.
+func (a *Archive) Open(name string, opts ...Option) (ShapeFiles, error) {
+	return OpenFS(a.zr, name, opts...)
+}
+
+// Metadata added by macOS archiver, such as __MACOSX/roads/._roads.shp
+func isArchiveJunk(name string) bool {
+	return strings.HasPrefix(name, `__MACOSX/`) || strings.HasPrefix(path.Base(name), `._`)
+}
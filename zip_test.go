@@ -0,0 +1,85 @@
+package geoesrishapefile
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+// Zip with two datasets, one in a subdirectory, and macOS metadata
+func testZip(t *testing.T) *bytes.Reader {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	add := func(name string, data []byte) {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = w.Write(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, ext := range []string{`.shp`, `.shx`, `.dbf`} {
+		for _, name := range []string{`point`, `polygon`} {
+			data, err := ioutil.ReadFile(filepath.Join(`_test_files`, name+ext))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			dir := ``
+			if name == `polygon` {
+				dir = `areas/`
+			}
+
+			add(dir+name+ext, data)
+			add(`__MACOSX/`+dir+`._`+name+ext, []byte(`junk`))
+		}
+	}
+
+	add(`areas/polygon.prj`, []byte(`GEOGCS["WGS 84"]`))
+	add(`readme.txt`, []byte(`not a dataset`))
+
+	err := zw.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return bytes.NewReader(buf.Bytes())
+}
+
+func TestArchive(t *testing.T) {
+	r := testZip(t)
+
+	a, err := NewZip(r, r.Size())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+
+	datasets := a.Datasets()
+	if len(datasets) != 2 || datasets[0].Name != `areas/polygon` || datasets[1].Name != `point` {
+		t.Fatalf(`datasets %v`, datasets)
+	}
+
+	if datasets[0].Files[`prj`] != `areas/polygon.prj` || len(datasets[0].Files) != 4 {
+		t.Errorf(`files %v`, datasets[0].Files)
+	}
+
+	for _, d := range datasets {
+		sf, err := a.Open(d.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		records, rows := countRecords(t, sf)
+		if records == 0 || records != rows {
+			t.Errorf(`%v: read %d records and %d rows`, d.Name, records, rows)
+		}
+	}
+}